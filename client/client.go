@@ -2,6 +2,7 @@ package client
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"time"
 
@@ -17,6 +18,10 @@ type Executor interface {
 	Execute(context.Context, ex.Request, any) (bool, error)
 }
 
+type Transactor interface {
+	Begin(context.Context) (ex.TxExecutor, error)
+}
+
 type Tracer interface {
 	StartSpan(context.Context, string, ...ex.SpanTag) (ex.Span, context.Context)
 }
@@ -24,6 +29,8 @@ type Tracer interface {
 type Client interface {
 	Exec(ex.Request, ...any) error
 	ExecContext(context.Context, ex.Request, ...any) error
+	Stream(context.Context, ex.Request, ex.StreamFunc) error
+	Tx(context.Context, func(Client) error) error
 }
 
 func WithExecutor(executor Executor) opt {
@@ -38,10 +45,18 @@ func WithTracer(tracer Tracer) opt {
 	}
 }
 
+// WithBackoff retries after each of the intervals in seconds. The first
+// interval was waited before the first attempt and is ignored.
+//
+// Deprecated: use WithRetryPolicy.
 func WithBackoff(backoff ...int) opt {
 	return func(c *client) {
 		if len(backoff) > 0 {
-			c.Backoff = backoff
+			var intervals Intervals
+			for _, interval := range backoff[1:] {
+				intervals = append(intervals, time.Duration(interval)*time.Second)
+			}
+			c.RetryPolicy = intervals
 		}
 	}
 }
@@ -51,9 +66,9 @@ type opt func(*client)
 func New(logger Logger, opts ...opt) *client {
 
 	client := &client{
-		Logger:  logger,
-		Tracer:  noopTracer{},
-		Backoff: []int{0, 2, 5, 10, 30},
+		Logger:      logger,
+		Tracer:      noopTracer{},
+		RetryPolicy: NewExponentialBackoff(),
 	}
 
 	for _, opt := range opts {
@@ -67,8 +82,7 @@ type client struct {
 	Logger
 	Executor
 	Tracer
-
-	Backoff []int
+	RetryPolicy
 }
 
 func (c *client) Exec(req ex.Request, res ...any) error {
@@ -87,23 +101,133 @@ func (c *client) ExecContext(ctx context.Context, req ex.Request, res ...any) er
 	}
 }
 
+// Stream passes each row to fn as it's read, holding the request open
+// until every row has been handled or fn returns an error.
+func (c *client) Stream(ctx context.Context, req ex.Request, fn ex.StreamFunc) error {
+	return c.ExecContext(ctx, req, fn)
+}
+
 func (c *client) execute(ctx context.Context, req ex.Request, data any) error {
 
-	var err error
-	var retry bool
+	var streamed bool
+
+	// Rows that were already streamed can't be taken back, so those
+	// requests are not retried
+	if fn, ok := data.(ex.StreamFunc); ok {
+		data = ex.StreamFunc(func(row map[string]any) error {
+			streamed = true
+			return fn(row)
+		})
+	}
+
+	return c.retry(ctx, "exec", func(ctx context.Context) (bool, error) {
+		retry, err := c.Executor.Execute(ctx, req, data)
+		if err != nil && streamed {
+			return false, finalError{err}
+		}
+		return retry, err
+	})
+}
+
+// finalError ends the retries without consulting the retry policy.
+type finalError struct {
+	error
+}
+
+// retry runs attempt until it succeeds or the retry policy gives up. Waits
+// between attempts end early when the context is done.
+func (c *client) retry(ctx context.Context, name string, attempt func(context.Context) (bool, error)) error {
+
+	start := time.Now()
+
+	for i := 0; ; i++ {
+		retry, err := c.attempt(ctx, name, i, attempt)
+		if err == nil {
+			return nil
+		}
+
+		if final, ok := err.(finalError); ok {
+			return final.error
+		}
+
+		delay, ok := c.RetryPolicy.Next(i+1, time.Since(start), err, retry)
+		if !ok {
+			return err
+		}
+
+		// A cancelled wait reports the error of the last attempt
+		if sleep(ctx, delay) != nil {
+			return err
+		}
+	}
+}
+
+func (c *client) attempt(ctx context.Context, name string, i int, attempt func(context.Context) (bool, error)) (bool, error) {
+
+	span, spanCtx := c.Tracer.StartSpan(ctx, name, ex.SpanTag{Key: "attempt", Value: i})
+	defer span.Finish()
+
+	return attempt(spanCtx)
+}
+
+// Tx runs fn in a transaction, committing it when fn succeeds and rolling it
+// back when fn errors or panics. The whole of fn is retried by the retry policy
+// when any of its requests hit a retryable failure, such as a deadlock.
+func (c *client) Tx(ctx context.Context, fn func(Client) error) error {
 
-	for i, interval := range c.Backoff {
-		time.Sleep(time.Duration(interval) * time.Second)
+	transactor, ok := c.Executor.(Transactor)
+	if !ok {
+		return errors.New("executor does not support transactions")
+	}
+
+	return c.retry(ctx, "tx", func(ctx context.Context) (bool, error) {
+		return c.tx(ctx, transactor, fn)
+	})
+}
+
+func (c *client) tx(ctx context.Context, transactor Transactor, fn func(Client) error) (bool, error) {
+
+	executor, err := transactor.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
 
-		span, spanCtx := c.Tracer.StartSpan(ctx, "exec", ex.SpanTag{Key: "attempt", Value: i})
-		defer span.Finish()
+	tx := &txExecutor{TxExecutor: executor}
 
-		if retry, err = c.Executor.Execute(spanCtx, req, data); !retry {
-			break
+	defer func() {
+		if r := recover(); r != nil {
+			executor.Rollback()
+			panic(r)
 		}
+	}()
+
+	// Requests are retried as part of the whole transaction instead
+	err = fn(&client{
+		Logger:      c.Logger,
+		Executor:    tx,
+		Tracer:      c.Tracer,
+		RetryPolicy: Intervals{},
+	})
+
+	if err != nil {
+		executor.Rollback()
+		return tx.retry, err
 	}
 
-	return err
+	return executor.Commit()
+}
+
+// txExecutor remembers whether any request of the transaction could have
+// been retried.
+type txExecutor struct {
+	ex.TxExecutor
+	retry bool
+}
+
+func (t *txExecutor) Execute(ctx context.Context, req ex.Request, data any) (bool, error) {
+	retry, err := t.TxExecutor.Execute(ctx, req, data)
+	t.retry = t.retry || retry
+	return false, err
 }
 
 type noopSpan struct{}
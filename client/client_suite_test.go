@@ -126,7 +126,7 @@ func (d *database) Close() {
 }
 
 func (d *database) Uri() string {
-	return d.uri + d.name
+	return d.uri + d.name + "?clientFoundRows=true"
 }
 
 func connection() string {
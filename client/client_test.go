@@ -1,6 +1,8 @@
 package client_test
 
 import (
+	"context"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
@@ -1423,4 +1425,23 @@ var _ = Describe("Client", func() {
 		ExpectUpdateBehaviour()
 		ExpectBulkBehaviour()
 	})
+
+	Describe("Rows affected", func() {
+		var meta *ex.Meta
+
+		BeforeEach(func() {
+			createResourcesTable()
+			insertResources("resource-1", "resource-2")
+
+			meta = &ex.Meta{}
+			ctx := ex.WithMeta(context.Background(), meta)
+			err = sqlClient.ExecContext(ctx, ex.Update("resources", ex.Values{"name": "resource-1"}))
+		})
+
+		It("counts the rows an update matched, even when unchanged", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(meta.RowsAffected).NotTo(BeNil())
+			Expect(*meta.RowsAffected).To(Equal(int64(2)))
+		})
+	})
 })
@@ -0,0 +1,117 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy decides whether a failed attempt is retried. Next returns how
+// long to wait before the given retry, or false to give up. Retryable is the
+// executor's classification of err.
+type RetryPolicy interface {
+	Next(attempt int, elapsed time.Duration, err error, retryable bool) (time.Duration, bool)
+}
+
+func WithRetryPolicy(policy RetryPolicy) opt {
+	return func(c *client) {
+		c.RetryPolicy = policy
+	}
+}
+
+// Intervals retries after each of its intervals in turn.
+type Intervals []time.Duration
+
+func (i Intervals) Next(attempt int, elapsed time.Duration, err error, retryable bool) (time.Duration, bool) {
+	if !Retryable(err, retryable) || attempt > len(i) {
+		return 0, false
+	}
+	return i[attempt-1], true
+}
+
+// ExponentialBackoff multiplies the interval after each retry, up to Max.
+// Jitter spreads each interval by up to that fraction in either direction,
+// so clients that failed together don't retry together. Zero MaxAttempts
+// or MaxElapsed don't limit the retries.
+type ExponentialBackoff struct {
+	Initial     time.Duration
+	Max         time.Duration
+	Multiplier  float64
+	Jitter      float64
+	MaxAttempts int
+	MaxElapsed  time.Duration
+}
+
+func NewExponentialBackoff() *ExponentialBackoff {
+	return &ExponentialBackoff{
+		Initial:     100 * time.Millisecond,
+		Max:         10 * time.Second,
+		Multiplier:  2,
+		Jitter:      0.2,
+		MaxAttempts: 5,
+		MaxElapsed:  30 * time.Second,
+	}
+}
+
+func (b *ExponentialBackoff) Next(attempt int, elapsed time.Duration, err error, retryable bool) (time.Duration, bool) {
+
+	if !Retryable(err, retryable) {
+		return 0, false
+	}
+
+	if b.MaxAttempts > 0 && attempt > b.MaxAttempts {
+		return 0, false
+	}
+
+	interval := float64(b.Initial) * math.Pow(b.Multiplier, float64(attempt-1))
+	if b.Max > 0 {
+		interval = math.Min(interval, float64(b.Max))
+	}
+
+	interval += interval * b.Jitter * (2*rand.Float64() - 1)
+	delay := time.Duration(interval)
+
+	if b.MaxElapsed > 0 && elapsed+delay > b.MaxElapsed {
+		return 0, false
+	}
+
+	return delay, true
+}
+
+// Retryable classifies err. The executor has the final say when it can't
+// retry, such as when the rows of a load were consumed, and otherwise errors
+// can classify themselves with a Retryable() bool method. Cancelled requests
+// are never retried.
+func Retryable(err error, retryable bool) bool {
+
+	if err == nil || !retryable {
+		return false
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var classified interface{ Retryable() bool }
+	if errors.As(err, &classified) {
+		return classified.Retryable()
+	}
+
+	return true
+}
+
+// sleep waits for the delay unless the context is done first.
+func sleep(ctx context.Context, delay time.Duration) error {
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,261 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/reverted/ex"
+	"github.com/reverted/ex/client"
+)
+
+var _ = Describe("Retry", func() {
+
+	var (
+		err error
+
+		ctx      context.Context
+		req      ex.Request
+		executor *fakeExecutor
+		policy   client.RetryPolicy
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		req = ex.Query("resources")
+		executor = &fakeExecutor{retry: true, err: errors.New("nope")}
+		policy = client.Intervals{0, 0}
+	})
+
+	JustBeforeEach(func() {
+		retryClient := client.New(newLogger(),
+			client.WithExecutor(executor),
+			client.WithTracer(noopTracer{}),
+			client.WithRetryPolicy(policy),
+		)
+
+		if executor.rows != nil {
+			err = retryClient.Stream(ctx, req, func(row map[string]any) error {
+				return nil
+			})
+		} else {
+			err = retryClient.ExecContext(ctx, req)
+		}
+	})
+
+	Context("when the request keeps failing", func() {
+		It("retries until the policy gives up", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(executor.attempts).To(Equal(3))
+		})
+	})
+
+	Context("when the request succeeds", func() {
+		BeforeEach(func() {
+			executor.err = nil
+		})
+
+		It("doesn't retry", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(executor.attempts).To(Equal(1))
+		})
+	})
+
+	Context("when the executor can't retry the error", func() {
+		BeforeEach(func() {
+			executor.retry = false
+		})
+
+		It("doesn't retry", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(executor.attempts).To(Equal(1))
+		})
+	})
+
+	Context("when the error classifies itself as retryable", func() {
+		BeforeEach(func() {
+			executor.err = fmt.Errorf("wrapped: %w", retryableError{true})
+		})
+
+		It("retries", func() {
+			Expect(executor.attempts).To(Equal(3))
+		})
+
+		Context("when the executor can't retry the request", func() {
+			BeforeEach(func() {
+				executor.retry = false
+			})
+
+			It("doesn't retry", func() {
+				Expect(executor.attempts).To(Equal(1))
+			})
+		})
+	})
+
+	Context("when the error classifies itself as not retryable", func() {
+		BeforeEach(func() {
+			executor.err = fmt.Errorf("wrapped: %w", retryableError{false})
+		})
+
+		It("doesn't retry", func() {
+			Expect(executor.attempts).To(Equal(1))
+		})
+	})
+
+	Context("when a load hits a retryable error", func() {
+		BeforeEach(func() {
+			req = ex.BulkLoad("resources", []string{"name"}, nil)
+
+			// The rows of a load are consumed by the first attempt
+			executor.retry = false
+			executor.err = ex.NewError(ex.Deadlock, errors.New("deadlock"))
+		})
+
+		It("doesn't retry", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(executor.attempts).To(Equal(1))
+		})
+	})
+
+	Context("when a stream fails after the first row", func() {
+		BeforeEach(func() {
+			executor.rows = []map[string]any{{"id": 1}}
+			executor.retry = false
+			executor.err = ex.NewError(ex.Unavailable, errors.New("gone away"))
+		})
+
+		It("doesn't retry", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(executor.attempts).To(Equal(1))
+		})
+	})
+
+	Context("when a stream the executor could retry fails after the first row", func() {
+		var recorder *recordingPolicy
+
+		BeforeEach(func() {
+			executor.rows = []map[string]any{{"id": 1}}
+			executor.err = fmt.Errorf("wrapped: %w", retryableError{true})
+
+			recorder = &recordingPolicy{RetryPolicy: policy}
+			policy = recorder
+		})
+
+		It("returns the error without consulting the policy", func() {
+			Expect(err).To(MatchError("wrapped: retryable"))
+			Expect(executor.attempts).To(Equal(1))
+			Expect(recorder.calls).To(BeZero())
+		})
+	})
+
+	Context("when the context is cancelled while waiting", func() {
+		BeforeEach(func() {
+			policy = client.Intervals{time.Minute}
+
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, 10*time.Millisecond)
+			DeferCleanup(cancel)
+		})
+
+		It("stops waiting", func() {
+			Expect(err).To(MatchError("nope"))
+			Expect(executor.attempts).To(Equal(1))
+		})
+	})
+})
+
+var _ = Describe("ExponentialBackoff", func() {
+
+	var backoff *client.ExponentialBackoff
+
+	BeforeEach(func() {
+		backoff = &client.ExponentialBackoff{
+			Initial:     time.Second,
+			Max:         5 * time.Second,
+			Multiplier:  2,
+			MaxAttempts: 5,
+			MaxElapsed:  time.Minute,
+		}
+	})
+
+	It("multiplies the interval up to the max", func() {
+		var delays []time.Duration
+		for attempt := 1; attempt <= 5; attempt++ {
+			delay, ok := backoff.Next(attempt, 0, errors.New("nope"), true)
+			Expect(ok).To(BeTrue())
+			delays = append(delays, delay)
+		}
+
+		Expect(delays).To(Equal([]time.Duration{
+			time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second,
+		}))
+	})
+
+	It("gives up after the max attempts", func() {
+		_, ok := backoff.Next(6, 0, errors.New("nope"), true)
+		Expect(ok).To(BeFalse())
+	})
+
+	It("gives up after the max elapsed time", func() {
+		_, ok := backoff.Next(1, time.Minute, errors.New("nope"), true)
+		Expect(ok).To(BeFalse())
+	})
+
+	It("doesn't retry cancelled requests", func() {
+		_, ok := backoff.Next(1, 0, context.Canceled, true)
+		Expect(ok).To(BeFalse())
+	})
+
+	Context("when the interval has jitter", func() {
+		BeforeEach(func() {
+			backoff.Jitter = 0.5
+		})
+
+		It("spreads the interval", func() {
+			delay, ok := backoff.Next(2, 0, errors.New("nope"), true)
+			Expect(ok).To(BeTrue())
+			Expect(delay).To(BeNumerically(">=", time.Second))
+			Expect(delay).To(BeNumerically("<=", 3*time.Second))
+		})
+	})
+})
+
+type fakeExecutor struct {
+	attempts int
+	rows     []map[string]any
+	retry    bool
+	err      error
+}
+
+func (e *fakeExecutor) Execute(ctx context.Context, req ex.Request, data any) (bool, error) {
+	e.attempts++
+	if fn, ok := data.(ex.StreamFunc); ok {
+		for _, row := range e.rows {
+			if err := fn(row); err != nil {
+				return false, err
+			}
+		}
+	}
+	return e.retry, e.err
+}
+
+type retryableError struct {
+	retryable bool
+}
+
+func (e retryableError) Error() string   { return "retryable" }
+func (e retryableError) Retryable() bool { return e.retryable }
+
+type recordingPolicy struct {
+	client.RetryPolicy
+	calls int
+}
+
+func (p *recordingPolicy) Next(attempt int, elapsed time.Duration, err error, retryable bool) (time.Duration, bool) {
+	p.calls++
+	return p.RetryPolicy.Next(attempt, elapsed, err, retryable)
+}
@@ -0,0 +1,156 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/reverted/ex"
+	"github.com/reverted/ex/client"
+)
+
+var _ = Describe("Tx", func() {
+
+	var (
+		err      error
+		panicked any
+
+		transactor *fakeTransactor
+		fn         func(client.Client) error
+
+		txClient client.Client
+	)
+
+	BeforeEach(func() {
+		transactor = &fakeTransactor{}
+
+		txClient = client.New(newLogger(),
+			client.WithExecutor(transactor),
+			client.WithTracer(noopTracer{}),
+			client.WithBackoff(0, 0, 0),
+		)
+	})
+
+	JustBeforeEach(func() {
+		defer func() {
+			panicked = recover()
+		}()
+
+		err = txClient.Tx(context.Background(), fn)
+	})
+
+	Context("when the closure succeeds", func() {
+		BeforeEach(func() {
+			fn = func(tx client.Client) error {
+				if err := tx.Exec(ex.Query("resources")); err != nil {
+					return err
+				}
+				return tx.Exec(ex.Insert("resources"))
+			}
+		})
+
+		It("runs every request in one transaction and commits it", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(transactor.txs).To(HaveLen(1))
+			Expect(transactor.txs[0].requests).To(HaveLen(2))
+			Expect(transactor.txs[0].committed).To(BeTrue())
+		})
+	})
+
+	Context("when the closure errors", func() {
+		BeforeEach(func() {
+			fn = func(tx client.Client) error {
+				return errors.New("nope")
+			}
+		})
+
+		It("rolls back", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(transactor.txs).To(HaveLen(1))
+			Expect(transactor.txs[0].rolledBack).To(BeTrue())
+		})
+	})
+
+	Context("when the closure panics", func() {
+		BeforeEach(func() {
+			fn = func(tx client.Client) error {
+				panic("nope")
+			}
+		})
+
+		It("rolls back and panics", func() {
+			Expect(panicked).To(Equal("nope"))
+			Expect(transactor.txs).To(HaveLen(1))
+			Expect(transactor.txs[0].rolledBack).To(BeTrue())
+		})
+	})
+
+	Context("when a request fails with a retryable error", func() {
+		BeforeEach(func() {
+			transactor.retries = 1
+
+			fn = func(tx client.Client) error {
+				return tx.Exec(ex.Query("resources"))
+			}
+		})
+
+		It("retries the whole transaction", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(transactor.txs).To(HaveLen(2))
+			Expect(transactor.txs[0].rolledBack).To(BeTrue())
+			Expect(transactor.txs[1].committed).To(BeTrue())
+		})
+	})
+
+	Context("when the executor doesn't support transactions", func() {
+		BeforeEach(func() {
+			txClient = client.New(newLogger(), client.WithTracer(noopTracer{}))
+		})
+
+		It("errors", func() {
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
+
+type fakeTransactor struct {
+	txs     []*fakeTx
+	retries int
+}
+
+func (t *fakeTransactor) Execute(ctx context.Context, req ex.Request, data any) (bool, error) {
+	return false, errors.New("not in a transaction")
+}
+
+func (t *fakeTransactor) Begin(ctx context.Context) (ex.TxExecutor, error) {
+	tx := &fakeTx{fail: len(t.txs) < t.retries}
+	t.txs = append(t.txs, tx)
+	return tx, nil
+}
+
+type fakeTx struct {
+	requests   []ex.Request
+	fail       bool
+	committed  bool
+	rolledBack bool
+}
+
+func (t *fakeTx) Execute(ctx context.Context, req ex.Request, data any) (bool, error) {
+	t.requests = append(t.requests, req)
+	if t.fail {
+		return true, errors.New("deadlock")
+	}
+	return false, nil
+}
+
+func (t *fakeTx) Commit() (bool, error) {
+	t.committed = true
+	return false, nil
+}
+
+func (t *fakeTx) Rollback() error {
+	t.rolledBack = true
+	return nil
+}
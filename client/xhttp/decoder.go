@@ -0,0 +1,148 @@
+package xhttp
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+)
+
+type rowReader interface {
+	Next() (map[string]any, error)
+}
+
+// newRowReader reads the rows of a response body in the format of its
+// content type. Rows are returned until io.EOF.
+func newRowReader(contentType string, body io.Reader) (rowReader, error) {
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = "application/json"
+	}
+
+	switch mediaType {
+	case "application/x-ndjson":
+		lines := &lineReader{reader: body}
+		return &ndjsonReader{decoder: json.NewDecoder(lines), lines: lines}, nil
+
+	case "text/csv":
+		lines := &lineReader{reader: body}
+		return &csvReader{reader: csv.NewReader(lines), lines: lines}, nil
+
+	default:
+		return &jsonReader{decoder: json.NewDecoder(body)}, nil
+	}
+}
+
+type jsonReader struct {
+	decoder *json.Decoder
+	started bool
+}
+
+func (r *jsonReader) Next() (map[string]any, error) {
+
+	if !r.started {
+		r.started = true
+
+		if token, err := r.decoder.Token(); err != nil {
+			return nil, err
+		} else if token != json.Delim('[') {
+			return nil, fmt.Errorf("unexpected token: %v", token)
+		}
+	}
+
+	if !r.decoder.More() {
+		if _, err := r.decoder.Token(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+
+	var row map[string]any
+	if err := r.decoder.Decode(&row); err != nil {
+		return nil, err
+	}
+
+	return row, nil
+}
+
+// lineReader remembers the last byte read. Rows end with a newline, so a body
+// that ends without one was cut short, such as by an aborted response.
+type lineReader struct {
+	reader io.Reader
+	last   byte
+}
+
+func (r *lineReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		r.last = p[n-1]
+	}
+	return n, err
+}
+
+func (r *lineReader) eof() error {
+	if r.last != 0 && r.last != '\n' {
+		return io.ErrUnexpectedEOF
+	}
+	return io.EOF
+}
+
+type ndjsonReader struct {
+	decoder *json.Decoder
+	lines   *lineReader
+}
+
+func (r *ndjsonReader) Next() (map[string]any, error) {
+
+	var row map[string]any
+	if err := r.decoder.Decode(&row); err == io.EOF {
+		return nil, r.lines.eof()
+	} else if err != nil {
+		return nil, err
+	}
+
+	return row, nil
+}
+
+// csvReader reads the columns from the header row. Every field is read as a
+// string, since csv doesn't carry types.
+type csvReader struct {
+	reader  *csv.Reader
+	lines   *lineReader
+	columns []string
+}
+
+func (r *csvReader) Next() (map[string]any, error) {
+
+	if r.columns == nil {
+		columns, err := r.read()
+		if err != nil {
+			return nil, err
+		}
+		r.columns = columns
+	}
+
+	record, err := r.read()
+	if err != nil {
+		return nil, err
+	}
+
+	row := map[string]any{}
+	for i, column := range r.columns {
+		row[column] = record[i]
+	}
+
+	return row, nil
+}
+
+func (r *csvReader) read() ([]string, error) {
+
+	record, err := r.reader.Read()
+	if err == io.EOF {
+		return nil, r.lines.eof()
+	}
+
+	return record, err
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 
 	"github.com/reverted/ex"
 )
@@ -23,6 +24,14 @@ type Formatter interface {
 	Format(ex.Request) (*http.Request, error)
 }
 
+type TxFormatter interface {
+	FormatTx(action, id string) (*http.Request, error)
+}
+
+type SchemaFormatter interface {
+	FormatSchema(resource string) (*http.Request, error)
+}
+
 type Client interface {
 	Do(*http.Request) (*http.Response, error)
 }
@@ -47,6 +56,14 @@ func WithTracer(tracer Tracer) opt {
 	}
 }
 
+// WithAccept requests responses in one of the content types the server can
+// encode: application/json, application/x-ndjson or text/csv.
+func WithAccept(contentType string) opt {
+	return func(e *executor) {
+		e.Accept = contentType
+	}
+}
+
 func WithClient(client Client) opt {
 	return func(e *executor) {
 		e.Client = client
@@ -76,45 +93,209 @@ type executor struct {
 	Formatter
 	Tracer
 	Client
+
+	Accept string
 }
 
 func (e *executor) Execute(ctx context.Context, req ex.Request, data any) (bool, error) {
 
-	r, err := e.Formatter.Format(req)
+	r, err := e.format(req, data)
 	if err != nil {
 		return false, err
 	}
 
-	return e.exec(ctx, r, data)
+	retry, err := e.exec(ctx, r, data)
+	return executeOnce(req, retry, err)
+}
+
+// executeOnce never retries a load, since its rows are consumed by the
+// first attempt.
+func executeOnce(req ex.Request, retry bool, err error) (bool, error) {
+	_, ok := req.(ex.Load)
+	return retry && !ok, err
+}
+
+// format formats the request, asking for the results of each request of a
+// batch when they're decoded into ex.Results.
+func (e *executor) format(req ex.Request, data any) (*http.Request, error) {
+
+	if batch, ok := req.(ex.Batch); ok {
+		_, batch.Results = data.(ex.Results)
+		req = batch
+	}
+
+	return e.Formatter.Format(req)
 }
 
 func (e *executor) exec(ctx context.Context, r *http.Request, data any) (bool, error) {
 
+	resp, retry, err := e.send(ctx, r)
+	if err != nil {
+		return retry, err
+	}
+
+	defer resp.Body.Close()
+
+	if meta := ex.MetaFromContext(ctx); meta != nil {
+		meta.NextCursor = resp.Header.Get("X-Next-Cursor")
+		meta.PrevCursor = resp.Header.Get("X-Prev-Cursor")
+
+		if param := resp.Header.Get("X-Total-Count"); param != "" {
+			total, err := strconv.ParseInt(param, 10, 64)
+			if err != nil {
+				return false, fmt.Errorf("invalid total count: %w", err)
+			}
+			meta.Total = &total
+		}
+
+		if param := resp.Header.Get("X-Rows-Affected"); param != "" {
+			affected, err := strconv.ParseInt(param, 10, 64)
+			if err != nil {
+				return false, fmt.Errorf("invalid rows affected: %w", err)
+			}
+			meta.RowsAffected = &affected
+		}
+	}
+
+	if data != nil {
+		return false, e.decode(resp, data)
+	}
+
+	return false, nil
+}
+
+// send returns the response when the server succeeds, or whether the
+// request can be retried when it doesn't.
+func (e *executor) send(ctx context.Context, r *http.Request) (*http.Response, bool, error) {
+
 	e.Logger.Infof(">>> %v", r.URL)
 
 	e.Tracer.InjectSpan(ctx, r)
 
+	if e.Accept != "" {
+		if r.Header == nil {
+			r.Header = http.Header{}
+		}
+		r.Header.Set("Accept", e.Accept)
+	}
+
 	resp, err := e.Client.Do(r.WithContext(ctx))
 	if err != nil {
-		return true, err
+		return nil, true, err
+	}
+
+	if resp.StatusCode < 400 {
+		return resp, false, nil
 	}
 
 	defer resp.Body.Close()
+	bodyBytes, _ := io.ReadAll(resp.Body)
+
+	// Servers that classify their errors report the type in the body
+	var body struct {
+		Type    ex.ErrorCode `json:"error_type"`
+		Message string       `json:"error_message"`
+	}
+	if json.Unmarshal(bodyBytes, &body) == nil && body.Type != "" {
+		err := &ex.Error{Code: body.Type, Message: body.Message}
+		return nil, err.Retryable(), err
+	}
 
-	switch {
-	case resp.StatusCode >= 500:
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return true, fmt.Errorf("server error: [%v] %s", resp.StatusCode, string(bodyBytes))
+	if resp.StatusCode >= 500 {
+		err = fmt.Errorf("server error: [%v] %s", resp.StatusCode, string(bodyBytes))
+	} else {
+		err = fmt.Errorf("client error: [%v] %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	if code, ok := statusErrorCodes[resp.StatusCode]; ok {
+		err = ex.NewError(code, err)
+	}
+
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusConflict
+	return nil, retry, err
+}
+
+var statusErrorCodes = map[int]ex.ErrorCode{
+	http.StatusBadRequest:         ex.Invalid,
+	http.StatusNotFound:           ex.NotFound,
+	http.StatusConflict:           ex.Conflict,
+	http.StatusTooManyRequests:    ex.Unavailable,
+	http.StatusServiceUnavailable: ex.Unavailable,
+	http.StatusGatewayTimeout:     ex.Timeout,
+}
+
+func (e *executor) decode(resp *http.Response, data any) error {
+
+	rows, err := newRowReader(resp.Header.Get("Content-Type"), resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if fn, ok := data.(ex.StreamFunc); ok {
+		return e.stream(rows, fn)
+	}
+
+	if results, ok := data.(ex.Results); ok {
+		return e.decodeResults(rows, results)
+	}
+
+	if _, ok := rows.(*jsonReader); ok {
+		return json.NewDecoder(resp.Body).Decode(data)
+	}
+
+	// Other formats are collected into json so they decode like json
+	var res []map[string]any
+	err = e.stream(rows, func(row map[string]any) error {
+		res = append(res, row)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, data)
+}
+
+// decodeResults decodes the data of each result of a batch into the
+// destination at its index.
+func (e *executor) decodeResults(rows rowReader, results ex.Results) error {
+
+	var i int
+	return e.stream(rows, func(row map[string]any) error {
+		defer func() { i++ }()
+
+		if i >= len(results) || results[i] == nil {
+			return nil
+		}
+
+		b, err := json.Marshal(row["data"])
+		if err != nil {
+			return err
+		}
+
+		return json.Unmarshal(b, results[i])
+	})
+}
 
-	case resp.StatusCode >= 400:
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return resp.StatusCode == 429, fmt.Errorf("client error: [%v] %s", resp.StatusCode, string(bodyBytes))
+func (e *executor) stream(rows rowReader, fn ex.StreamFunc) error {
 
-	case data != nil:
-		return false, json.NewDecoder(resp.Body).Decode(data)
+	for {
+		row, err := rows.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
 
-	default:
-		return false, nil
+		if err := fn(row); err != nil {
+			return err
+		}
 	}
 }
 
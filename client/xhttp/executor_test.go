@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"testing/iotest"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -27,14 +28,17 @@ var _ = Describe("Executor", func() {
 		err   error
 		retry bool
 
-		req ex.Request
-		res interface{}
+		req     ex.Request
+		res     interface{}
+		stream  ex.StreamFunc
+		results ex.Results
 
 		mockCtrl      *gomock.Controller
 		mockClient    *mocks.MockClient
 		mockFormatter *mocks.MockFormatter
 
 		ctx      context.Context
+		meta     *ex.Meta
 		executor Executor
 	)
 
@@ -45,7 +49,11 @@ var _ = Describe("Executor", func() {
 		mockClient = mocks.NewMockClient(mockCtrl)
 		mockFormatter = mocks.NewMockFormatter(mockCtrl)
 
-		ctx = context.Background()
+		stream = nil
+		results = nil
+
+		meta = &ex.Meta{}
+		ctx = ex.WithMeta(context.Background(), meta)
 
 		executor = xhttp.NewExecutor(
 			logger,
@@ -56,7 +64,13 @@ var _ = Describe("Executor", func() {
 	})
 
 	JustBeforeEach(func() {
-		retry, err = executor.Execute(ctx, req, &res)
+		if stream != nil {
+			retry, err = executor.Execute(ctx, req, stream)
+		} else if results != nil {
+			retry, err = executor.Execute(ctx, req, results)
+		} else {
+			retry, err = executor.Execute(ctx, req, &res)
+		}
 	})
 
 	Context("when running a query", func() {
@@ -78,6 +92,37 @@ var _ = Describe("Executor", func() {
 			})
 		})
 
+		Context("when requesting a content type", func() {
+			var httpReq *http.Request
+
+			BeforeEach(func() {
+				executor = xhttp.NewExecutor(
+					newLogger(),
+					xhttp.WithClient(mockClient),
+					xhttp.WithFormatter(mockFormatter),
+					xhttp.WithTracer(noopTracer{}),
+					xhttp.WithAccept("text/csv"),
+				)
+
+				httpReq = &http.Request{Header: http.Header{}}
+
+				mockFormatter.EXPECT().Format(req).Return(httpReq, nil)
+				mockClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(r *http.Request) (*http.Response, error) {
+					Expect(r.Header.Get("Accept")).To(Equal("text/csv"))
+					return &http.Response{
+						StatusCode: 200,
+						Header:     http.Header{"Content-Type": []string{"text/csv"}},
+						Body:       io.NopCloser(bytes.NewBufferString("key\nvalue\n")),
+					}, nil
+				})
+			})
+
+			It("decodes the requested content type", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(res).To(ConsistOf(map[string]interface{}{"key": "value"}))
+			})
+		})
+
 		Context("when formatting the request succeeds", func() {
 			var httpReq *http.Request
 			var httpResp *http.Response
@@ -138,6 +183,37 @@ var _ = Describe("Executor", func() {
 					})
 				})
 
+				Context("when the server responds with a typed error", func() {
+					BeforeEach(func() {
+						httpResp.StatusCode = 409
+						httpResp.Body = io.NopCloser(bytes.NewBufferString(`{"error_type": "constraint_violation", "error_message": "duplicate"}`))
+					})
+
+					It("decodes the error", func() {
+						Expect(err).To(MatchError("duplicate"))
+						Expect(ex.CodeOf(err)).To(Equal(ex.ConstraintViolation))
+					})
+
+					It("should not retry", func() {
+						Expect(retry).To(BeFalse())
+					})
+				})
+
+				Context("when the server responds with an untyped conflict", func() {
+					BeforeEach(func() {
+						httpResp.StatusCode = 409
+						httpResp.Body = io.NopCloser(bytes.NewBufferString(``))
+					})
+
+					It("classifies the error by its status", func() {
+						Expect(ex.CodeOf(err)).To(Equal(ex.Conflict))
+					})
+
+					It("should retry", func() {
+						Expect(retry).To(BeTrue())
+					})
+				})
+
 				Context("when the server responds with a success status", func() {
 					BeforeEach(func() {
 						httpResp.StatusCode = 200
@@ -152,6 +228,161 @@ var _ = Describe("Executor", func() {
 						Expect(retry).To(BeFalse())
 					})
 
+					Context("when the server responds with a next cursor", func() {
+						BeforeEach(func() {
+							httpResp.Header = http.Header{"X-Next-Cursor": []string{"some-cursor"}}
+						})
+
+						It("captures the next cursor", func() {
+							Expect(meta.NextCursor).To(Equal("some-cursor"))
+						})
+					})
+
+					Context("when the server responds with a cursor to turn back", func() {
+						BeforeEach(func() {
+							httpResp.Header = http.Header{"X-Prev-Cursor": []string{"some-cursor"}}
+						})
+
+						It("captures the cursor to turn back", func() {
+							Expect(meta.PrevCursor).To(Equal("some-cursor"))
+						})
+					})
+
+					Context("when the server responds with a total count", func() {
+						BeforeEach(func() {
+							httpResp.Header = http.Header{"X-Total-Count": []string{"42"}}
+						})
+
+						It("captures the total", func() {
+							Expect(meta.Total).NotTo(BeNil())
+							Expect(*meta.Total).To(Equal(int64(42)))
+						})
+					})
+
+					Context("when the server responds with rows affected", func() {
+						BeforeEach(func() {
+							httpResp.Header = http.Header{"X-Rows-Affected": []string{"3"}}
+						})
+
+						It("captures the rows affected", func() {
+							Expect(meta.RowsAffected).NotTo(BeNil())
+							Expect(*meta.RowsAffected).To(Equal(int64(3)))
+						})
+					})
+
+					Context("when streaming the result", func() {
+						var rows []map[string]interface{}
+
+						BeforeEach(func() {
+							rows = nil
+							stream = func(row map[string]interface{}) error {
+								rows = append(rows, row)
+								return nil
+							}
+						})
+
+						It("succeeds", func() {
+							Expect(err).NotTo(HaveOccurred())
+						})
+
+						It("streams each row", func() {
+							Expect(rows).To(ConsistOf(map[string]interface{}{
+								"key": "value",
+							}))
+						})
+					})
+
+					Context("when streaming a truncated result", func() {
+						BeforeEach(func() {
+							httpResp.Body = io.NopCloser(bytes.NewBufferString(`[{"key": "value"},`))
+							stream = func(row map[string]interface{}) error {
+								return nil
+							}
+						})
+
+						It("errors", func() {
+							Expect(err).To(HaveOccurred())
+						})
+					})
+
+					Context("when the server responds with ndjson", func() {
+						BeforeEach(func() {
+							httpResp.Header = http.Header{"Content-Type": []string{"application/x-ndjson"}}
+							httpResp.Body = io.NopCloser(bytes.NewBufferString("{\"key\": \"value\"}\n{\"key\": \"other\"}\n"))
+							res = []map[string]interface{}{}
+						})
+
+						It("captures the result", func() {
+							Expect(err).NotTo(HaveOccurred())
+							Expect(res).To(ConsistOf(
+								map[string]interface{}{"key": "value"},
+								map[string]interface{}{"key": "other"},
+							))
+						})
+					})
+
+					Context("when an ndjson response is cut short", func() {
+						BeforeEach(func() {
+							httpResp.Header = http.Header{"Content-Type": []string{"application/x-ndjson"}}
+							httpResp.Body = io.NopCloser(bytes.NewBufferString("{\"key\": \"value\"}\n{\"key\": \"other\"}"))
+							res = []map[string]interface{}{}
+						})
+
+						It("errors", func() {
+							Expect(err).To(MatchError(io.ErrUnexpectedEOF))
+						})
+					})
+
+					Context("when the connection of an ndjson response is aborted", func() {
+						BeforeEach(func() {
+							httpResp.Header = http.Header{"Content-Type": []string{"application/x-ndjson"}}
+							httpResp.Body = io.NopCloser(io.MultiReader(
+								bytes.NewBufferString("{\"key\": \"value\"}\n"),
+								iotest.ErrReader(io.ErrUnexpectedEOF),
+							))
+							res = []map[string]interface{}{}
+						})
+
+						It("errors", func() {
+							Expect(err).To(MatchError(io.ErrUnexpectedEOF))
+						})
+					})
+
+					Context("when a csv response is cut short", func() {
+						BeforeEach(func() {
+							httpResp.Header = http.Header{"Content-Type": []string{"text/csv"}}
+							httpResp.Body = io.NopCloser(bytes.NewBufferString("id,key\n1,value\n2,oth"))
+							res = []map[string]interface{}{}
+						})
+
+						It("errors", func() {
+							Expect(err).To(MatchError(io.ErrUnexpectedEOF))
+						})
+					})
+
+					Context("when the server responds with csv", func() {
+						var rows []map[string]interface{}
+
+						BeforeEach(func() {
+							httpResp.Header = http.Header{"Content-Type": []string{"text/csv"}}
+							httpResp.Body = io.NopCloser(bytes.NewBufferString("id,key\n1,value\n2,other\n"))
+
+							rows = nil
+							stream = func(row map[string]interface{}) error {
+								rows = append(rows, row)
+								return nil
+							}
+						})
+
+						It("streams each row", func() {
+							Expect(err).NotTo(HaveOccurred())
+							Expect(rows).To(Equal([]map[string]interface{}{
+								{"id": "1", "key": "value"},
+								{"id": "2", "key": "other"},
+							}))
+						})
+					})
+
 					Context("when providing a result interface", func() {
 						BeforeEach(func() {
 							res = []map[string]interface{}{}
@@ -171,6 +402,28 @@ var _ = Describe("Executor", func() {
 			})
 		})
 	})
+
+	Context("when running a batch with results", func() {
+		var first, second []map[string]any
+
+		BeforeEach(func() {
+			req = ex.Bulk(ex.Query("resources"), ex.Query("others"))
+			results = ex.Results{&first, &second}
+
+			httpReq := &http.Request{}
+			mockFormatter.EXPECT().Format(ex.Batch{Requests: []ex.Request{ex.Query("resources"), ex.Query("others")}, Results: true}).Return(httpReq, nil)
+			mockClient.EXPECT().Do(httpReq.WithContext(ctx)).Return(&http.Response{
+				StatusCode: 200,
+				Body:       io.NopCloser(bytes.NewBufferString(`[{"data": [{"key": "value"}]}, {"data": []}]`)),
+			}, nil)
+		})
+
+		It("decodes each result into its destination", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(first).To(Equal([]map[string]any{{"key": "value"}}))
+			Expect(second).To(BeEmpty())
+		})
+	})
 })
 
 type noopSpan struct{}
@@ -33,6 +33,9 @@ func (f *formatter) Format(req ex.Request) (*http.Request, error) {
 	case ex.Batch:
 		return f.FormatBatch(c)
 
+	case ex.Load:
+		return f.FormatLoad(c)
+
 	default:
 		return nil, errors.New("unsupported req")
 	}
@@ -64,6 +67,71 @@ func (f *formatter) FormatBatch(batch ex.Batch) (*http.Request, error) {
 	return http.NewRequest("POST", url.String(), body)
 }
 
+// FormatTx formats the :begin, :commit or :rollback request of a transaction.
+func (f *formatter) FormatTx(action, id string) (*http.Request, error) {
+
+	url := *f.URL
+	url.Path = path.Join(url.Path, action)
+
+	r, err := http.NewRequest("POST", url.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if id != "" {
+		r.Header.Set("X-Transaction", id)
+	}
+
+	return r, nil
+}
+
+// FormatSchema formats the request for the schema of a resource.
+func (f *formatter) FormatSchema(resource string) (*http.Request, error) {
+
+	url := *f.URL
+	url.Path = path.Join(url.Path, ":schema", resource)
+
+	return http.NewRequest("GET", url.String(), nil)
+}
+
+// FormatLoad streams the rows as NDJSON arrays ordered by X-Columns.
+func (f *formatter) FormatLoad(load ex.Load) (*http.Request, error) {
+
+	reader, writer := io.Pipe()
+
+	go func() {
+		encoder := json.NewEncoder(writer)
+		for {
+			row, err := load.Rows.Next()
+			if err == io.EOF {
+				writer.Close()
+				return
+			}
+			if err == nil {
+				err = encoder.Encode(row)
+			}
+			if err != nil {
+				writer.CloseWithError(err)
+				return
+			}
+		}
+	}()
+
+	url := *f.URL
+	url.Path = path.Join(url.Path, load.Resource)
+
+	r, err := http.NewRequest("POST", url.String(), reader)
+	if err != nil {
+		reader.Close()
+		return nil, err
+	}
+
+	r.Header.Set("Content-Type", "application/x-ndjson")
+	r.Header.Set("X-Columns", strings.Join(load.Columns, ","))
+
+	return r, nil
+}
+
 func (f *formatter) FormatCommand(cmd ex.Command) (*http.Request, error) {
 
 	switch strings.ToUpper(cmd.Action) {
@@ -84,7 +152,12 @@ func (f *formatter) FormatRequest(cmd ex.Command) (*http.Request, error) {
 		return nil, err
 	}
 
-	body, err := f.FormatBodyForMethod(method, cmd.Values)
+	var values any = cmd.Values
+	if len(cmd.RowsConfig) > 0 {
+		values = cmd.RowsConfig
+	}
+
+	body, err := f.FormatBodyForMethod(method, values)
 	if err != nil {
 		return nil, err
 	}
@@ -114,17 +187,41 @@ func (f *formatter) FormatParams(cmd ex.Command) (url.Values, error) {
 
 	params := url.Values{}
 
-	for k, v := range cmd.Where {
-		key, value, err := ex.FormatWhereArg(k, v)
-		if err != nil {
-			return nil, err
-		}
-		params.Add(key, fmt.Sprintf("%v", value))
+	if err := f.formatConditions(params, "", cmd.Where); err != nil {
+		return nil, err
+	}
+
+	if err := f.formatConditions(params, "having.", ex.Where(cmd.Having)); err != nil {
+		return nil, err
 	}
 
 	return params, nil
 }
 
+func (f *formatter) formatConditions(params url.Values, prefix string, where ex.Where) error {
+
+	for k, v := range where {
+		args, ok := v.(ex.AllArg)
+		if !ok {
+			args = ex.AllArg{v}
+		}
+
+		for _, arg := range args {
+			key, value, err := ex.FormatWhereArg(k, arg)
+			if err != nil {
+				return err
+			}
+			if raw, ok := value.(json.RawMessage); ok {
+				params.Add(prefix+key, string(raw))
+			} else {
+				params.Add(prefix+key, fmt.Sprintf("%v", value))
+			}
+		}
+	}
+
+	return nil
+}
+
 func (f *formatter) FormatHeaders(cmd ex.Command) (map[string]string, error) {
 	res := map[string]string{}
 
@@ -132,6 +229,18 @@ func (f *formatter) FormatHeaders(cmd ex.Command) (map[string]string, error) {
 		res["X-Columns"] = strings.Join(cmd.ColumnConfig, ",")
 	}
 
+	if len(cmd.JoinConfig) > 0 {
+		var joins []string
+		for _, join := range cmd.JoinConfig {
+			joinType := "INNER"
+			if join.Type != "" {
+				joinType = strings.ToUpper(join.Type)
+			}
+			joins = append(joins, fmt.Sprintf("%s %s ON %s", joinType, join.Resource, strings.Join(join.On, " AND ")))
+		}
+		res["X-Join"] = strings.Join(joins, ",")
+	}
+
 	if len(cmd.GroupConfig) > 0 {
 		res["X-Group-By"] = strings.Join(cmd.GroupConfig, ",")
 	}
@@ -148,10 +257,34 @@ func (f *formatter) FormatHeaders(cmd ex.Command) (map[string]string, error) {
 		res["X-Offset"] = fmt.Sprintf("%v", cmd.OffsetConfig)
 	}
 
+	if cmd.TotalConfig {
+		res["X-Total"] = "true"
+	}
+
+	if cmd.ReadPrimaryConfig {
+		res["X-Read-Primary"] = "true"
+	}
+
+	if c := cmd.CursorConfig.After; c != "" {
+		res["X-Cursor"] = c
+	}
+
+	if c := cmd.CursorConfig.Before; c != "" {
+		res["X-Cursor"] = "before " + c
+	}
+
 	if len(cmd.PartitionConfig) > 0 {
 		res["X-Partition-By"] = strings.Join(cmd.PartitionConfig, ",")
 	}
 
+	if len(cmd.PrimaryKeyConfig) > 0 {
+		res["X-Primary-Key"] = strings.Join(cmd.PrimaryKeyConfig, ",")
+	}
+
+	if len(cmd.ReturningConfig) > 0 {
+		res["X-Returning"] = strings.Join(cmd.ReturningConfig, ",")
+	}
+
 	if c := cmd.OnConflictConfig.Constraint; len(c) > 0 {
 		res["X-On-Conflict-Constraint"] = strings.Join(c, ",")
 	}
@@ -171,7 +304,7 @@ func (f *formatter) FormatHeaders(cmd ex.Command) (map[string]string, error) {
 	return res, nil
 }
 
-func (f *formatter) FormatBodyForMethod(method string, values ex.Values) (io.Reader, error) {
+func (f *formatter) FormatBodyForMethod(method string, values any) (io.Reader, error) {
 
 	switch method {
 	case "PUT", "POST":
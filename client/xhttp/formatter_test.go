@@ -45,6 +45,23 @@ var _ = Describe("Formatter", func() {
 		})
 	})
 
+	Context("when the request is a bulk load", func() {
+		BeforeEach(func() {
+			req = ex.BulkLoad("resources", []string{"id", "name"}, ex.IterateRows(
+				[]any{1, "some-name"},
+				[]any{2, nil},
+			))
+		})
+
+		It("streams the rows as ndjson", func() {
+			Expect(res.Method).To(Equal("POST"))
+			Expect(res.URL.String()).To(Equal("http://some.url/resources"))
+			Expect(res.Header.Get("Content-Type")).To(Equal("application/x-ndjson"))
+			Expect(res.Header.Get("X-Columns")).To(Equal("id,name"))
+			Expect(io.ReadAll(res.Body)).To(Equal([]byte("[1,\"some-name\"]\n[2,null]\n")))
+		})
+	})
+
 	Context("when the command action is not supported", func() {
 		BeforeEach(func() {
 			req = ex.Command{Action: "some-action"}
@@ -76,6 +93,35 @@ var _ = Describe("Formatter", func() {
 			})
 		})
 
+		Context("when the request has multiple predicates on a column", func() {
+			BeforeEach(func() {
+				req = ex.Query("resources", ex.Where{"id": ex.All(ex.Gt(1), ex.NotEq(5)), "name": ex.All(ex.NotLike("a%"), ex.NotLike("b%"))})
+			})
+
+			It("formats the request", func() {
+				Expect(res.Method).To(Equal("GET"))
+				Expect(res.URL.Query()).To(Equal(url.Values{
+					"id:gt":         []string{"1"},
+					"id:not_eq":     []string{"5"},
+					"name:not_like": []string{"a%", "b%"},
+				}))
+			})
+		})
+
+		Context("when the request has condition groups", func() {
+			BeforeEach(func() {
+				req = ex.Query("resources", ex.Or(
+					ex.Where{"status": "active"},
+					ex.Not(ex.Where{"owner_id": ex.Gt(5)}),
+				))
+			})
+
+			It("formats the request", func() {
+				Expect(res.Method).To(Equal("GET"))
+				Expect(res.URL.Query().Get(":or")).To(MatchJSON(`[{"status":"active"},{":not":{"owner_id:gt":"5"}}]`))
+			})
+		})
+
 		Context("when the request has columns", func() {
 			BeforeEach(func() {
 				req = ex.Query("resources", ex.Columns("key"))
@@ -88,6 +134,33 @@ var _ = Describe("Formatter", func() {
 			})
 		})
 
+		Context("when the request has joins", func() {
+			BeforeEach(func() {
+				req = ex.Query("orders",
+					ex.Join("customers", "orders.customer_id = customers.id"),
+					ex.LeftJoin("addresses", "addresses.customer_id = customers.id", "addresses.primary = orders.primary"),
+				)
+			})
+
+			It("formats the request", func() {
+				Expect(res.Method).To(Equal("GET"))
+				Expect(res.URL.String()).To(Equal("http://some.url/orders"))
+				Expect(res.Header.Get("X-Join")).To(Equal("INNER customers ON orders.customer_id = customers.id,LEFT addresses ON addresses.customer_id = customers.id AND addresses.primary = orders.primary"))
+			})
+		})
+
+		Context("when the request has having", func() {
+			BeforeEach(func() {
+				req = ex.Query("resources", ex.GroupBy("key"), ex.Having{"COUNT(id)": ex.Gt(10)})
+			})
+
+			It("formats the request", func() {
+				Expect(res.Method).To(Equal("GET"))
+				Expect(res.URL.Query()).To(Equal(url.Values{"having.COUNT(id):gt": []string{"10"}}))
+				Expect(res.Header.Get("X-Group-By")).To(Equal("key"))
+			})
+		})
+
 		Context("when the request has group by", func() {
 			BeforeEach(func() {
 				req = ex.Query("resources", ex.GroupBy("key"))
@@ -136,6 +209,52 @@ var _ = Describe("Formatter", func() {
 			})
 		})
 
+		Context("when the request has total", func() {
+			BeforeEach(func() {
+				req = ex.Query("resources", ex.WithTotal())
+			})
+
+			It("formats the request", func() {
+				Expect(res.Method).To(Equal("GET"))
+				Expect(res.URL.String()).To(Equal("http://some.url/resources"))
+				Expect(res.Header.Get("X-Total")).To(Equal("true"))
+			})
+		})
+
+		Context("when the request reads from the primary", func() {
+			BeforeEach(func() {
+				req = ex.Query("resources", ex.ReadPrimary())
+			})
+
+			It("formats the request", func() {
+				Expect(res.Header.Get("X-Read-Primary")).To(Equal("true"))
+			})
+		})
+
+		Context("when the request has an after cursor", func() {
+			BeforeEach(func() {
+				req = ex.Query("resources", ex.Order("id"), ex.After("some-cursor"))
+			})
+
+			It("formats the request", func() {
+				Expect(res.Method).To(Equal("GET"))
+				Expect(res.URL.String()).To(Equal("http://some.url/resources"))
+				Expect(res.Header.Get("X-Cursor")).To(Equal("some-cursor"))
+			})
+		})
+
+		Context("when the request has a before cursor", func() {
+			BeforeEach(func() {
+				req = ex.Query("resources", ex.Order("id"), ex.Before("some-cursor"))
+			})
+
+			It("formats the request", func() {
+				Expect(res.Method).To(Equal("GET"))
+				Expect(res.URL.String()).To(Equal("http://some.url/resources"))
+				Expect(res.Header.Get("X-Cursor")).To(Equal("before some-cursor"))
+			})
+		})
+
 		Context("when the request has partition by", func() {
 			BeforeEach(func() {
 				req = ex.Query("resources", ex.PartitionBy("user_id"))
@@ -267,6 +386,40 @@ var _ = Describe("Formatter", func() {
 			})
 		})
 
+		Context("when the request has returning", func() {
+			BeforeEach(func() {
+				req = ex.Insert("resources", ex.Returning("id", "name"))
+			})
+
+			It("formats the request", func() {
+				Expect(res.Method).To(Equal("POST"))
+				Expect(res.Header.Get("X-Returning")).To(Equal("id,name"))
+			})
+		})
+
+		Context("when the request has rows", func() {
+			BeforeEach(func() {
+				req = ex.Insert("resources", ex.Rows(ex.Values{"key": "value1"}, ex.Values{"key": "value2"}))
+			})
+
+			It("formats the rows as an array body", func() {
+				body, err := io.ReadAll(res.Body)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(body).To(MatchJSON(`[{"key": "value1"}, {"key": "value2"}]`))
+			})
+		})
+
+		Context("when the request has a primary key", func() {
+			BeforeEach(func() {
+				req = ex.Insert("resources", ex.PrimaryKey("tenant_id", "uuid"))
+			})
+
+			It("formats the request", func() {
+				Expect(res.Method).To(Equal("POST"))
+				Expect(res.Header.Get("X-Primary-Key")).To(Equal("tenant_id,uuid"))
+			})
+		})
+
 		Context("when the request has conflict error", func() {
 			BeforeEach(func() {
 				req = ex.Insert("resources", ex.OnConflictError("true"))
@@ -0,0 +1,38 @@
+package xhttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/reverted/ex"
+)
+
+// Describe fetches the columns, keys and indexes of a resource from the
+// server's schema endpoint.
+func (e *executor) Describe(ctx context.Context, resource string) (ex.Schema, error) {
+
+	formatter, ok := e.Formatter.(SchemaFormatter)
+	if !ok {
+		return ex.Schema{}, errors.New("formatter does not support describe")
+	}
+
+	r, err := formatter.FormatSchema(resource)
+	if err != nil {
+		return ex.Schema{}, err
+	}
+
+	resp, _, err := e.send(ctx, r)
+	if err != nil {
+		return ex.Schema{}, err
+	}
+
+	defer resp.Body.Close()
+
+	var schema ex.Schema
+	if err := json.NewDecoder(resp.Body).Decode(&schema); err != nil {
+		return ex.Schema{}, err
+	}
+
+	return schema, nil
+}
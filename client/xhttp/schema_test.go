@@ -0,0 +1,91 @@
+package xhttp_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/golang/mock/gomock"
+	"github.com/reverted/ex"
+	"github.com/reverted/ex/client/xhttp"
+	"github.com/reverted/ex/client/xhttp/mocks"
+)
+
+type Describer interface {
+	Describe(context.Context, string) (ex.Schema, error)
+}
+
+var _ = Describe("Describe", func() {
+
+	var (
+		err    error
+		schema ex.Schema
+
+		mockCtrl   *gomock.Controller
+		mockClient *mocks.MockClient
+
+		request   *http.Request
+		status    int
+		body      string
+		describer Describer
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = mocks.NewMockClient(mockCtrl)
+
+		target, err := url.Parse("http://some.url/v1")
+		Expect(err).NotTo(HaveOccurred())
+
+		status = http.StatusOK
+		body = `{"resource": "resources", "columns": [{"name": "id", "type": "int", "nullable": false}], "primary_key": ["id"]}`
+
+		mockClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(r *http.Request) (*http.Response, error) {
+			request = r
+			return &http.Response{
+				StatusCode: status,
+				Body:       io.NopCloser(bytes.NewBufferString(body)),
+			}, nil
+		})
+
+		describer = xhttp.NewExecutor(newLogger(),
+			xhttp.WithClient(mockClient),
+			xhttp.WithFormatter(xhttp.NewFormatter(target)),
+			xhttp.WithTracer(noopTracer{}),
+		)
+	})
+
+	JustBeforeEach(func() {
+		schema, err = describer.Describe(context.Background(), "resources")
+	})
+
+	It("fetches the schema of the resource", func() {
+		Expect(err).NotTo(HaveOccurred())
+		Expect(request.Method).To(Equal("GET"))
+		Expect(request.URL.Path).To(Equal("/v1/:schema/resources"))
+	})
+
+	It("decodes the schema", func() {
+		Expect(schema).To(Equal(ex.Schema{
+			Resource:   "resources",
+			Columns:    []ex.Column{{Name: "id", Type: "int"}},
+			PrimaryKey: []string{"id"},
+		}))
+	})
+
+	Context("when the resource is unknown", func() {
+		BeforeEach(func() {
+			status = http.StatusNotFound
+			body = `{"error_type": "not_found", "error_message": "unknown resource: resources"}`
+		})
+
+		It("errors", func() {
+			Expect(ex.CodeOf(err)).To(Equal(ex.NotFound))
+		})
+	})
+})
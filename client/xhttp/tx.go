@@ -0,0 +1,94 @@
+package xhttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/reverted/ex"
+)
+
+// Begin starts a transaction on the server. Requests executed through the
+// returned executor carry its id in the X-Transaction header.
+func (e *executor) Begin(ctx context.Context) (ex.TxExecutor, error) {
+
+	formatter, ok := e.Formatter.(TxFormatter)
+	if !ok {
+		return nil, errors.New("formatter does not support transactions")
+	}
+
+	r, err := formatter.FormatTx(":begin", "")
+	if err != nil {
+		return nil, err
+	}
+
+	if opts, ok := ex.TxOptionsFromContext(ctx); ok {
+		if opts.Isolation != "" {
+			r.Header.Set("X-Isolation", string(opts.Isolation))
+		}
+		if opts.ReadOnly {
+			r.Header.Set("X-Read-Only", "true")
+		}
+	}
+
+	resp, _, err := e.send(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Body.Close()
+
+	id := resp.Header.Get("X-Transaction")
+	if id == "" {
+		return nil, errors.New("missing transaction id")
+	}
+
+	return &txExecutor{executor: e, formatter: formatter, id: id}, nil
+}
+
+type txExecutor struct {
+	executor  *executor
+	formatter TxFormatter
+	id        string
+}
+
+func (t *txExecutor) Execute(ctx context.Context, req ex.Request, data any) (bool, error) {
+
+	r, err := t.executor.format(req, data)
+	if err != nil {
+		return false, err
+	}
+
+	if r.Header == nil {
+		r.Header = http.Header{}
+	}
+	r.Header.Set("X-Transaction", t.id)
+
+	retry, err := t.executor.exec(ctx, r, data)
+	return executeOnce(req, retry, err)
+}
+
+func (t *txExecutor) Commit() (bool, error) {
+	return t.end(":commit")
+}
+
+func (t *txExecutor) Rollback() error {
+	_, err := t.end(":rollback")
+	return err
+}
+
+func (t *txExecutor) end(action string) (bool, error) {
+
+	r, err := t.formatter.FormatTx(action, t.id)
+	if err != nil {
+		return false, err
+	}
+
+	resp, retry, err := t.executor.send(context.Background(), r)
+	if err != nil {
+		return retry, err
+	}
+
+	resp.Body.Close()
+	return false, nil
+}
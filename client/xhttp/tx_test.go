@@ -0,0 +1,175 @@
+package xhttp_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/golang/mock/gomock"
+	"github.com/reverted/ex"
+	"github.com/reverted/ex/client/xhttp"
+	"github.com/reverted/ex/client/xhttp/mocks"
+)
+
+type Transactor interface {
+	Begin(context.Context) (ex.TxExecutor, error)
+}
+
+var _ = Describe("TxExecutor", func() {
+
+	var (
+		err error
+
+		mockCtrl   *gomock.Controller
+		mockClient *mocks.MockClient
+
+		ctx        context.Context
+		requests   []*http.Request
+		statuses   []int
+		transactor Transactor
+		txExecutor ex.TxExecutor
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockClient = mocks.NewMockClient(mockCtrl)
+
+		target, err := url.Parse("http://some.url")
+		Expect(err).NotTo(HaveOccurred())
+
+		ctx = context.Background()
+		requests = nil
+		statuses = nil
+
+		mockClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(r *http.Request) (*http.Response, error) {
+			requests = append(requests, r)
+
+			status := http.StatusOK
+			if len(statuses) > 0 {
+				status, statuses = statuses[0], statuses[1:]
+			}
+
+			header := http.Header{}
+			if r.URL.Path == "/:begin" {
+				header.Set("X-Transaction", "some-id")
+			}
+
+			body := `[]`
+			if r.URL.Path == "/:batch" {
+				body = `[{"data": [{"key": "value"}]}]`
+			}
+
+			return &http.Response{
+				StatusCode: status,
+				Header:     header,
+				Body:       io.NopCloser(bytes.NewBufferString(body)),
+			}, nil
+		}).AnyTimes()
+
+		transactor = xhttp.NewExecutor(newLogger(),
+			xhttp.WithClient(mockClient),
+			xhttp.WithFormatter(xhttp.NewFormatter(target)),
+			xhttp.WithTracer(noopTracer{}),
+		)
+	})
+
+	JustBeforeEach(func() {
+		txExecutor, err = transactor.Begin(ctx)
+	})
+
+	Context("when beginning a tx fails", func() {
+		BeforeEach(func() {
+			statuses = []int{http.StatusNotImplemented}
+		})
+
+		It("errors", func() {
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("when beginning a tx succeeds", func() {
+		It("begins the tx", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(requests).To(HaveLen(1))
+			Expect(requests[0].Method).To(Equal("POST"))
+			Expect(requests[0].URL.String()).To(Equal("http://some.url/:begin"))
+		})
+
+		It("executes every request in the tx and commits it", func() {
+			var res []map[string]any
+
+			retry, err := txExecutor.Execute(context.Background(), ex.Query("resources"), &res)
+			Expect(retry).To(BeFalse())
+			Expect(err).NotTo(HaveOccurred())
+
+			retry, err = txExecutor.Commit()
+			Expect(retry).To(BeFalse())
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(requests).To(HaveLen(3))
+			Expect(requests[1].URL.String()).To(Equal("http://some.url/resources"))
+			Expect(requests[1].Header.Get("X-Transaction")).To(Equal("some-id"))
+			Expect(requests[2].URL.String()).To(Equal("http://some.url/:commit"))
+			Expect(requests[2].Header.Get("X-Transaction")).To(Equal("some-id"))
+		})
+
+		It("asks for the results of a batch decoded into results", func() {
+			var first []map[string]any
+
+			_, err := txExecutor.Execute(context.Background(), ex.Bulk(ex.Query("resources")), ex.Results{&first})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(requests).To(HaveLen(2))
+			body, err := io.ReadAll(requests[1].Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(body)).To(ContainSubstring(`"results":true`))
+			Expect(first).To(Equal([]map[string]any{{"key": "value"}}))
+		})
+
+		It("rolls back the tx", func() {
+			Expect(txExecutor.Rollback()).To(Succeed())
+
+			Expect(requests).To(HaveLen(2))
+			Expect(requests[1].URL.String()).To(Equal("http://some.url/:rollback"))
+			Expect(requests[1].Header.Get("X-Transaction")).To(Equal("some-id"))
+		})
+
+		It("reports conflicts as retryable", func() {
+			statuses = []int{http.StatusConflict}
+
+			retry, err := txExecutor.Execute(context.Background(), ex.Query("resources"), nil)
+			Expect(retry).To(BeTrue())
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("when the context has tx options", func() {
+		BeforeEach(func() {
+			ctx = ex.WithTxOptions(ctx, ex.TxOptions{Isolation: ex.Serializable, ReadOnly: true})
+		})
+
+		It("sends the options", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(requests[0].Header.Get("X-Isolation")).To(Equal("SERIALIZABLE"))
+			Expect(requests[0].Header.Get("X-Read-Only")).To(Equal("true"))
+		})
+	})
+
+	Context("when the formatter doesn't support transactions", func() {
+		BeforeEach(func() {
+			transactor = xhttp.NewExecutor(newLogger(),
+				xhttp.WithClient(mockClient),
+				xhttp.WithFormatter(mocks.NewMockFormatter(mockCtrl)),
+			)
+		})
+
+		It("errors", func() {
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
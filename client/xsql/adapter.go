@@ -39,8 +39,8 @@ type conn struct {
 	*sql.DB
 }
 
-func (c *conn) Begin() (Tx, error) {
-	t, err := c.DB.Begin()
+func (c *conn) BeginTx(ctx context.Context, opts *sql.TxOptions) (Tx, error) {
+	t, err := c.DB.BeginTx(ctx, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -83,6 +83,26 @@ func (t *tx) Exec(stmt string, args ...any) (Result, error) {
 	return &result{r}, nil
 }
 
+func (t *tx) PrepareContext(ctx context.Context, query string) (Stmt, error) {
+	s, err := t.Tx.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return &stmt{s}, nil
+}
+
+type stmt struct {
+	*sql.Stmt
+}
+
+func (s *stmt) ExecContext(ctx context.Context, args ...any) (Result, error) {
+	r, err := s.Stmt.ExecContext(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+	return &result{r}, nil
+}
+
 type rows struct {
 	*sql.Rows
 }
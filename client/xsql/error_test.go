@@ -0,0 +1,93 @@
+package xsql_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
+	"github.com/reverted/ex"
+	"github.com/reverted/ex/client/xsql"
+	"github.com/reverted/ex/client/xsql/mocks"
+)
+
+var _ = Describe("Errors", func() {
+
+	var (
+		err   error
+		retry bool
+
+		mockCtrl       *gomock.Controller
+		mockConnection *mocks.MockConnection
+
+		ctx context.Context
+	)
+
+	execute := func(executor Executor, driverErr error) {
+		mockConnection.EXPECT().BeginTx(gomock.Any(), gomock.Any()).Return(nil, driverErr)
+		retry, err = executor.Execute(ctx, ex.Query("resources"), nil)
+	}
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockConnection = mocks.NewMockConnection(mockCtrl)
+
+		ctx = context.Background()
+	})
+
+	Context("with the postgres formatter", func() {
+		var executor Executor
+
+		BeforeEach(func() {
+			executor = xsql.NewExecutor(newLogger(),
+				xsql.WithConnection(mockConnection),
+				xsql.WithPostgresFormatter(),
+				xsql.WithTracer(noopTracer{}),
+			)
+		})
+
+		It("retries deadlocks", func() {
+			execute(executor, &pq.Error{Code: "40P01"})
+			Expect(retry).To(BeTrue())
+			Expect(ex.CodeOf(err)).To(Equal(ex.Deadlock))
+		})
+
+		It("retries serialization failures", func() {
+			execute(executor, &pq.Error{Code: "40001"})
+			Expect(retry).To(BeTrue())
+			Expect(ex.CodeOf(err)).To(Equal(ex.Conflict))
+		})
+
+		It("doesn't retry constraint violations", func() {
+			execute(executor, &pq.Error{Code: "23505"})
+			Expect(retry).To(BeFalse())
+			Expect(ex.CodeOf(err)).To(Equal(ex.ConstraintViolation))
+		})
+	})
+
+	Context("with the mysql formatter", func() {
+		var executor Executor
+
+		BeforeEach(func() {
+			executor = xsql.NewExecutor(newLogger(),
+				xsql.WithConnection(mockConnection),
+				xsql.WithTracer(noopTracer{}),
+			)
+		})
+
+		It("retries deadlocks", func() {
+			execute(executor, &mysql.MySQLError{Number: 1213})
+			Expect(retry).To(BeTrue())
+			Expect(ex.CodeOf(err)).To(Equal(ex.Deadlock))
+		})
+
+		It("doesn't retry constraint violations", func() {
+			execute(executor, &mysql.MySQLError{Number: 1062})
+			Expect(retry).To(BeFalse())
+			Expect(ex.CodeOf(err)).To(Equal(ex.ConstraintViolation))
+		})
+	})
+})
@@ -2,15 +2,20 @@ package xsql
 
 import (
 	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"reflect"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
 	"github.com/reverted/ex"
 	"github.com/reverted/ex/client/xsql/xmysql"
 	"github.com/reverted/ex/client/xsql/xpg"
@@ -28,16 +33,48 @@ type Validator interface {
 	Validate(ex.Command, map[string]string) error
 }
 
+// PatternValidator reports the expressions it permits in place of a column.
+type PatternValidator interface {
+	PermittedColumnPatterns() []string
+}
+
 type Formatter interface {
 	Format(ex.Command, map[string]string) (ex.Statement, error)
 }
 
+type ReturningFormatter interface {
+	SupportsReturning() bool
+}
+
+type PrimaryKeyFormatter interface {
+	FormatPrimaryKey(string) ex.Statement
+}
+
+type SchemaFormatter interface {
+	FormatResourceNames() ex.Statement
+	FormatColumnSchema(string) ex.Statement
+	FormatIndexSchema(string) ex.Statement
+	FormatForeignKeySchema(string) ex.Statement
+}
+
+type ErrorFormatter interface {
+	FormatError(error) error
+}
+
+type BulkFormatter interface {
+	MaxPlaceholders() int
+}
+
+type Loader interface {
+	Load(context.Context, Tx, ex.Load) error
+}
+
 type Scanner interface {
 	Scan(Rows, any) error
 }
 
 type Connection interface {
-	Begin() (Tx, error)
+	BeginTx(context.Context, *sql.TxOptions) (Tx, error)
 }
 
 type Tx interface {
@@ -46,9 +83,15 @@ type Tx interface {
 	Query(string, ...any) (Rows, error)
 	ExecContext(context.Context, string, ...any) (Result, error)
 	Exec(string, ...any) (Result, error)
+	PrepareContext(context.Context, string) (Stmt, error)
 	Commit() error
 }
 
+type Stmt interface {
+	ExecContext(context.Context, ...any) (Result, error)
+	Close() error
+}
+
 type Rows interface {
 	Err() error
 	Next() bool
@@ -65,6 +108,7 @@ type ColumnType interface {
 
 type Result interface {
 	LastInsertId() (int64, error)
+	RowsAffected() (int64, error)
 }
 
 type opt func(*executor)
@@ -72,12 +116,14 @@ type opt func(*executor)
 func WithPostgresFormatter() opt {
 	return func(e *executor) {
 		e.Formatter = xpg.NewFormatter()
+		e.Loader = NewCopyLoader()
 	}
 }
 
 func WithMysqlFormatter() opt {
 	return func(e *executor) {
 		e.Formatter = xmysql.NewFormatter()
+		e.Loader = NewLocalInfileLoader()
 	}
 }
 
@@ -87,6 +133,12 @@ func WithFormatter(formatter Formatter) opt {
 	}
 }
 
+func WithLoader(loader Loader) opt {
+	return func(e *executor) {
+		e.Loader = loader
+	}
+}
+
 func WithScanner(scanner Scanner) opt {
 	return func(e *executor) {
 		e.Scanner = scanner
@@ -111,6 +163,28 @@ func WithConnection(connection Connection) opt {
 	}
 }
 
+func WithPrimaryKey(resource string, columns ...string) opt {
+	return func(e *executor) {
+		e.PrimaryKeys[resource] = columns
+	}
+}
+
+// WithReplicas sends queries outside of transactions to the replicas in
+// turn. Everything else goes to the primary connection.
+func WithReplicas(replicas ...Connection) opt {
+	return func(e *executor) {
+		e.Replicas = append(e.Replicas, replicas...)
+	}
+}
+
+// WithTxOptions sets the options of transactions whose context doesn't have
+// any from ex.WithTxOptions.
+func WithTxOptions(opts ex.TxOptions) opt {
+	return func(e *executor) {
+		e.TxOptions = opts
+	}
+}
+
 func WithTypeCacheDuration(duration time.Duration) opt {
 	return func(e *executor) {
 		e.TypeCacheDuration = duration
@@ -125,6 +199,8 @@ func NewExecutor(logger Logger, opts ...opt) *executor {
 		Scanner:           NewScanner(),
 		Validator:         NewValidator(logger),
 		Formatter:         xmysql.NewFormatter(),
+		Loader:            NewLocalInfileLoader(),
+		PrimaryKeys:       map[string][]string{},
 		TypeCache:         TypeCache{},
 		TypeCacheDuration: time.Hour,
 	}
@@ -135,7 +211,7 @@ func NewExecutor(logger Logger, opts ...opt) *executor {
 
 	// This calls dial so this should only get initialized if conn is nil
 	if executor.Connection == nil {
-		WithConnection(NewConn("mysql", "tcp(localhost:3306)/dev"))(executor)
+		WithConnection(NewConn("mysql", "tcp(localhost:3306)/dev?clientFoundRows=true"))(executor)
 	}
 
 	return executor
@@ -150,39 +226,235 @@ type executor struct {
 	Formatter
 	Validator
 	Connection
+	Loader
 
+	Replicas          []Connection
+	replica           atomic.Uint64
+	PrimaryKeys       map[string][]string
+	TxOptions         ex.TxOptions
 	TypeCache         TypeCache
 	TypeCacheDuration time.Duration
 }
 
 func (e *executor) Execute(ctx context.Context, req ex.Request, data any) (bool, error) {
-	err := e.execute(ctx, req, data)
+	retry, err := e.classify(e.execute(ctx, req, data))
+	return executeOnce(req, retry, err)
+}
+
+// formatError classifies driver errors as ex.Errors when the formatter
+// knows how.
+func (e *executor) formatError(err error) error {
+	if f, ok := e.Formatter.(ErrorFormatter); ok && err != nil {
+		return f.FormatError(err)
+	}
+	return err
+}
+
+// classify formats err and decides whether it's retried, from its
+// ex.Error code when the formatter knows the dialect's errors.
+func (e *executor) classify(err error) (bool, error) {
+	formatted := e.formatError(err)
+
+	var exErr *ex.Error
+	if errors.As(formatted, &exErr) {
+		return exErr.Retryable(), formatted
+	}
 
+	return isRetryable(err), formatted
+}
+
+func isRetryable(err error) bool {
 	switch t := err.(type) {
 	case *mysql.MySQLError:
-		return (t.Number == 1213), err // retry on deadlock
+		return (t.Number == 1213) // retry on deadlock
+
+	case *pq.Error:
+		// retry on serialization failure or deadlock
+		return t.Code == "40001" || t.Code == "40P01"
 
 	default:
-		return false, err
+		return false
 	}
 }
 
+// executeOnce never retries a load, since its rows are consumed by the
+// first attempt.
+func executeOnce(req ex.Request, retry bool, err error) (bool, error) {
+	return retry && !isLoad(req), err
+}
+
+func isLoad(req ex.Request) bool {
+	switch c := req.(type) {
+	case ex.Load:
+		return true
+
+	case ex.Batch:
+		for _, r := range c.Requests {
+			if isLoad(r) {
+				return true
+			}
+		}
+		return false
+
+	default:
+		return false
+	}
+}
+
+// connection picks a replica for reads that don't need the primary.
+func (e *executor) connection(req ex.Request) Connection {
+
+	if len(e.Replicas) == 0 || !isRead(req) {
+		return e.Connection
+	}
+
+	n := e.replica.Add(1) - 1
+	return e.Replicas[n%uint64(len(e.Replicas))]
+}
+
+func isRead(req ex.Request) bool {
+	switch c := req.(type) {
+	case ex.Command:
+		return c.Action == "QUERY" && !bool(c.ReadPrimaryConfig)
+
+	case ex.Batch:
+		var reads int
+		for _, r := range c.Requests {
+			if _, ok := r.(ex.Instruction); ok {
+				continue
+			}
+			if !isRead(r) {
+				return false
+			}
+			reads++
+		}
+		return reads > 0
+
+	default:
+		return false
+	}
+}
+
+func (e *executor) begin(ctx context.Context, conn Connection) (Tx, error) {
+
+	opts, ok := ex.TxOptionsFromContext(ctx)
+	if !ok {
+		opts = e.TxOptions
+	}
+
+	var isolation sql.IsolationLevel
+
+	switch opts.Isolation {
+	case "":
+		isolation = sql.LevelDefault
+	case ex.ReadCommitted:
+		isolation = sql.LevelReadCommitted
+	case ex.RepeatableRead:
+		isolation = sql.LevelRepeatableRead
+	case ex.Serializable:
+		isolation = sql.LevelSerializable
+	default:
+		return nil, fmt.Errorf("unsupported isolation level: %s", opts.Isolation)
+	}
+
+	return conn.BeginTx(ctx, &sql.TxOptions{
+		Isolation: isolation,
+		ReadOnly:  opts.ReadOnly,
+	})
+}
+
 func (e *executor) execute(ctx context.Context, req ex.Request, data any) error {
 
-	tx, err := e.Connection.Begin()
+	tx, err := e.begin(ctx, e.connection(req))
 	if err != nil {
 		return err
 	}
 
 	defer tx.Rollback()
 
-	if err = e.executeTx(ctx, tx, req, data); err != nil {
+	if err = e.executeMeta(ctx, tx, req, data); err != nil {
 		return err
 	}
 
 	return tx.Commit()
 }
 
+// executeMeta reports the rows affected by the writes of the request in the
+// meta of the context.
+func (e *executor) executeMeta(ctx context.Context, tx Tx, req ex.Request, data any) error {
+
+	meta := ex.MetaFromContext(ctx)
+	if meta == nil || isQuery(req) {
+		return e.executeTx(ctx, tx, req, data)
+	}
+
+	var affected int64
+	if err := e.executeTx(context.WithValue(ctx, affectedKey{}, &affected), tx, req, data); err != nil {
+		return err
+	}
+
+	meta.RowsAffected = &affected
+	return nil
+}
+
+func isQuery(req ex.Request) bool {
+	switch c := req.(type) {
+	case ex.Command:
+		return c.Action == "QUERY"
+
+	case ex.Batch:
+		for _, r := range c.Requests {
+			if _, ok := r.(ex.Instruction); !ok && !isQuery(r) {
+				return false
+			}
+		}
+		return true
+
+	default:
+		return false
+	}
+}
+
+// Begin starts a transaction that every request executed through the
+// returned executor shares.
+func (e *executor) Begin(ctx context.Context) (ex.TxExecutor, error) {
+
+	tx, err := e.begin(ctx, e.Connection)
+	if err != nil {
+		return nil, err
+	}
+
+	return &txExecutor{executor: e, tx: tx}, nil
+}
+
+type txExecutor struct {
+	executor *executor
+	tx       Tx
+	sync.Mutex
+}
+
+func (t *txExecutor) Execute(ctx context.Context, req ex.Request, data any) (bool, error) {
+	t.Lock()
+	defer t.Unlock()
+
+	retry, err := t.executor.classify(t.executor.executeMeta(ctx, t.tx, req, data))
+	return executeOnce(req, retry, err)
+}
+
+func (t *txExecutor) Commit() (bool, error) {
+	t.Lock()
+	defer t.Unlock()
+
+	return t.executor.classify(t.tx.Commit())
+}
+
+func (t *txExecutor) Rollback() error {
+	t.Lock()
+	defer t.Unlock()
+
+	return t.tx.Rollback()
+}
+
 func (e *executor) executeTx(ctx context.Context, tx Tx, req ex.Request, data any) error {
 
 	switch c := req.(type) {
@@ -198,6 +470,9 @@ func (e *executor) executeTx(ctx context.Context, tx Tx, req ex.Request, data an
 	case ex.Batch:
 		return e.batch(ctx, tx, c, data)
 
+	case ex.Load:
+		return e.load(ctx, tx, c, data)
+
 	default:
 		return errors.New("unsupported req")
 	}
@@ -205,13 +480,17 @@ func (e *executor) executeTx(ctx context.Context, tx Tx, req ex.Request, data an
 
 func (e *executor) cmd(ctx context.Context, tx Tx, cmd ex.Command, data any) error {
 
-	cols, err := e.getColumnTypes(ctx, tx, cmd.Resource)
+	cols, err := e.getCommandColumnTypes(ctx, tx, cmd)
 	if err != nil {
 		return err
 	}
 
+	if len(cmd.PrimaryKeyConfig) == 0 {
+		cmd.PrimaryKeyConfig = e.getPrimaryKey(cmd.Resource)
+	}
+
 	if err := e.Validator.Validate(cmd, cols); err != nil {
-		return fmt.Errorf("invalid command: %w", err)
+		return ex.NewError(ex.Invalid, fmt.Errorf("invalid command: %w", err))
 	}
 
 	switch strings.ToUpper(cmd.Action) {
@@ -234,6 +513,10 @@ func (e *executor) cmd(ctx context.Context, tx Tx, cmd ex.Command, data any) err
 
 func (e *executor) query(ctx context.Context, tx Tx, cmd ex.Command, cols map[string]string, data any) error {
 
+	if _, ok := data.(ex.StreamFunc); ok && cmd.CursorConfig.Before != "" {
+		return errors.New("before cursors can't be streamed")
+	}
+
 	stmt, err := e.Formatter.Format(cmd, cols)
 	if err != nil {
 		return err
@@ -249,11 +532,214 @@ func (e *executor) query(ctx context.Context, tx Tx, cmd ex.Command, cols map[st
 
 	defer rows.Close()
 
-	return e.Scanner.Scan(rows, data)
+	// The values of the next cursor are kept as the rows are scanned
+	var cursor *cursorRows
+	if ex.MetaFromContext(ctx) != nil && len(cmd.OrderConfig) > 0 && cmd.LimitConfig > 0 {
+		cursor = &cursorRows{Rows: rows}
+		rows = cursor
+	}
+
+	if err := e.Scanner.Scan(rows, data); err != nil {
+		return err
+	}
+
+	if err := e.paginate(ctx, cmd, cursor, data); err != nil {
+		return err
+	}
+
+	if meta := ex.MetaFromContext(ctx); meta != nil && cmd.TotalConfig {
+		total, err := e.count(spanCtx, tx, cmd, cols)
+		if err != nil {
+			return err
+		}
+		meta.Total = &total
+	}
+
+	return nil
+}
+
+// count wraps the query without its paging in a COUNT(*) so that joins,
+// groups and partitions are counted the same way they are returned.
+func (e *executor) count(ctx context.Context, tx Tx, cmd ex.Command, cols map[string]string) (int64, error) {
+
+	cmd.OrderConfig = nil
+	cmd.CursorConfig = ex.CursorConfig{}
+	cmd.TotalConfig = false
+
+	// A partitioned limit applies to each partition rather than the page
+	if len(cmd.PartitionConfig) == 0 {
+		cmd.LimitConfig = 0
+		cmd.OffsetConfig = 0
+	}
+
+	// The rows are counted without their columns, which can repeat names
+	// across joined tables. Having can refer to the aliases of the columns,
+	// so they're kept for it.
+	if len(cmd.Having) == 0 {
+		cmd.ColumnConfig = ex.ColumnConfig{"1"}
+	}
+
+	stmt, err := e.Formatter.Format(cmd, cols)
+	if err != nil {
+		return 0, err
+	}
+
+	stmt.Stmt = fmt.Sprintf("SELECT COUNT(*) FROM (%s) AS counted", stmt.Stmt)
+
+	rows, err := e.queryContext(ctx, tx, stmt)
+	if err != nil {
+		return 0, err
+	}
+
+	defer rows.Close()
+
+	var total int64
+	if rows.Next() {
+		if err := rows.Scan(&total); err != nil {
+			return 0, err
+		}
+	}
+
+	return total, rows.Err()
+}
+
+// paginate restores the requested order of a Before page, which is queried
+// in reverse, and reports the cursors to turn back from a paged query and to
+// follow it when the page is full.
+func (e *executor) paginate(ctx context.Context, cmd ex.Command, cursor *cursorRows, data any) error {
+
+	v := reflect.ValueOf(data)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Slice {
+		return nil
+	}
+
+	rows := v.Elem()
+	backward := cmd.CursorConfig.Before != ""
+
+	if backward {
+		swap := reflect.Swapper(rows.Interface())
+		for i, j := 0, rows.Len()-1; i < j; i, j = i+1, j-1 {
+			swap(i, j)
+		}
+	}
+
+	if cursor == nil || rows.Len() == 0 {
+		return nil
+	}
+
+	meta := ex.MetaFromContext(ctx)
+
+	// The first row scanned borders the page the cursor came from, which
+	// is its last row when it was queried in reverse
+	if cmd.CursorConfig.After != "" || backward {
+		prev, err := encodeCursor(cmd, cursor.first)
+		if err != nil {
+			return err
+		}
+		meta.PrevCursor = prev
+	}
+
+	if rows.Len() < int(cmd.LimitConfig) {
+		return nil
+	}
+
+	// The last row scanned ends the page, which is its first row when it
+	// was queried in reverse
+	next, err := encodeCursor(cmd, cursor.values)
+	if err != nil {
+		return err
+	}
+
+	meta.NextCursor = next
+	return nil
+}
+
+// encodeCursor encodes the values of the order columns of a row.
+func encodeCursor(cmd ex.Command, row map[string]any) (string, error) {
+
+	var values []any
+	for _, order := range cmd.OrderConfig {
+		column, _ := ex.ParseOrder(order)
+		column = column[strings.LastIndex(column, ".")+1:]
+
+		value, ok := row[column]
+		if !ok {
+			return "", fmt.Errorf("missing cursor column: %s", column)
+		}
+		values = append(values, value)
+	}
+
+	return ex.EncodeCursor(values...)
+}
+
+// cursorRows keeps the values of the first and last rows scanned by column
+// name, since the fields they're scanned into can be named differently.
+type cursorRows struct {
+	Rows
+	columns []string
+	first   map[string]any
+	values  map[string]any
+}
+
+func (r *cursorRows) Scan(dest ...any) error {
+
+	if err := r.Rows.Scan(dest...); err != nil {
+		return err
+	}
+
+	if r.columns == nil {
+		types, err := r.Rows.ColumnTypes()
+		if err != nil {
+			return err
+		}
+
+		r.columns = []string{}
+		for _, t := range types {
+			r.columns = append(r.columns, t.Name())
+		}
+	}
+
+	r.values = map[string]any{}
+	for i, column := range r.columns {
+		if i < len(dest) {
+			r.values[column] = cursorValue(dest[i])
+		}
+	}
+
+	if r.first == nil {
+		r.first = r.values
+	}
+
+	return nil
+}
+
+// cursorValue reads the value scanned into dest. Bytes are copied, since the
+// driver can reuse them for the next row.
+func cursorValue(dest any) any {
+
+	var value any
+	if valuer, ok := dest.(driver.Valuer); ok {
+		value, _ = valuer.Value()
+	} else if v := reflect.ValueOf(dest); v.Kind() == reflect.Ptr && !v.IsNil() {
+		value = v.Elem().Interface()
+	}
+
+	switch t := value.(type) {
+	case sql.RawBytes:
+		return string(t)
+	case []byte:
+		return string(t)
+	default:
+		return t
+	}
 }
 
 func (e *executor) delete(ctx context.Context, tx Tx, cmd ex.Command, cols map[string]string, data any) error {
 
+	if data != nil && e.supportsReturning() {
+		return e.returning(ctx, tx, "delete", cmd, cols, data)
+	}
+
 	stmt, err := e.Formatter.Format(cmd, cols)
 	if err != nil {
 		return err
@@ -274,6 +760,14 @@ func (e *executor) delete(ctx context.Context, tx Tx, cmd ex.Command, cols map[s
 
 func (e *executor) insert(ctx context.Context, tx Tx, cmd ex.Command, cols map[string]string, data any) error {
 
+	if len(cmd.RowsConfig) > 0 {
+		return e.insertRows(ctx, tx, cmd, cols, data)
+	}
+
+	if data != nil && e.supportsReturning() {
+		return e.returning(ctx, tx, "insert", cmd, cols, data)
+	}
+
 	stmt, err := e.Formatter.Format(cmd, cols)
 	if err != nil {
 		return err
@@ -288,24 +782,226 @@ func (e *executor) insert(ctx context.Context, tx Tx, cmd ex.Command, cols map[s
 	}
 
 	if data != nil {
-		id, err := res.LastInsertId()
-		if err != nil {
-			return e.Scanner.Scan(emptyRows{}, data)
+		key := cmd.PrimaryKeyConfig
+		if len(key) == 0 {
+			key = []string{"id"}
 		}
 
-		if id == 0 {
-			return e.Scanner.Scan(emptyRows{}, data)
+		where := ex.Where{}
+		for _, column := range key {
+			if value, ok := cmd.Values[column]; ok {
+				where[column] = value
+			}
 		}
 
-		q := ex.Query(cmd.Resource, ex.Where{"id": id})
+		// Without every key column in the values we can only fall back to
+		// the generated id, which only identifies single column keys
+		if len(where) != len(key) {
+			if len(key) > 1 {
+				return e.Scanner.Scan(emptyRows{}, data)
+			}
+
+			id, err := res.LastInsertId()
+			if err != nil {
+				return e.Scanner.Scan(emptyRows{}, data)
+			}
+
+			if id == 0 {
+				return e.Scanner.Scan(emptyRows{}, data)
+			}
+
+			where = ex.Where{key[0]: id}
+		}
+
+		q := ex.Query(cmd.Resource, where)
 		return e.query(spanCtx, tx, q, cols, data)
 	}
 
 	return nil
 }
 
+func (e *executor) insertRows(ctx context.Context, tx Tx, cmd ex.Command, cols map[string]string, data any) error {
+
+	span, spanCtx := e.Tracer.StartSpan(ctx, "insert")
+	defer span.Finish()
+
+	returning := data != nil && e.supportsReturning()
+	if returning {
+		if len(cmd.ReturningConfig) == 0 {
+			cmd.ReturningConfig = ex.ReturningConfig{"*"}
+		}
+		resetSlice(data)
+	}
+
+	// Generated ids only follow on from the first one when every row takes
+	// one. Rows that are ignored or updated on conflict, or that set their
+	// own key, can only be queried again by their primary key.
+	conflicts := len(cmd.OnConflictConfig.Update) > 0 || cmd.OnConflictConfig.Ignore != ""
+	if data != nil && !returning && (conflicts || setsKey(cmd)) && e.rowsWhere(cmd, nil) == nil {
+		return ex.NewError(ex.Invalid, errors.New("inserted rows can only be returned with their primary key"))
+	}
+
+	var ids []any
+
+	for _, rows := range e.chunkRows(cmd.RowsConfig) {
+		chunk := cmd
+		chunk.RowsConfig = rows
+
+		stmt, err := e.Formatter.Format(chunk, cols)
+		if err != nil {
+			return err
+		}
+
+		if returning {
+			if err := e.queryAppend(spanCtx, tx, stmt, data); err != nil {
+				return err
+			}
+			continue
+		}
+
+		res, err := e.execContext(spanCtx, tx, stmt)
+		if err != nil {
+			return err
+		}
+
+		// MySQL assigns consecutive ids to the rows of a single multi-row
+		// insert and reports the first one
+		if data != nil {
+			if id, err := res.LastInsertId(); err == nil && id > 0 {
+				for i := range rows {
+					ids = append(ids, id+int64(i))
+				}
+			}
+		}
+	}
+
+	if data == nil || returning {
+		return nil
+	}
+
+	where := e.rowsWhere(cmd, ids)
+	if where == nil {
+		return e.Scanner.Scan(emptyRows{}, data)
+	}
+
+	q := ex.Query(cmd.Resource, where)
+	return e.query(spanCtx, tx, q, cols, data)
+}
+
+func rowsKey(cmd ex.Command) []string {
+	if len(cmd.PrimaryKeyConfig) == 0 {
+		return []string{"id"}
+	}
+	return cmd.PrimaryKeyConfig
+}
+
+func (e *executor) chunkRows(rows ex.RowsConfig) []ex.RowsConfig {
+
+	f, ok := e.Formatter.(BulkFormatter)
+	if !ok {
+		return []ex.RowsConfig{rows}
+	}
+
+	size := f.MaxPlaceholders() / max(len(rows.Columns()), 1)
+	if size < 1 {
+		size = 1
+	}
+
+	var chunks []ex.RowsConfig
+	for len(rows) > size {
+		chunks = append(chunks, rows[:size])
+		rows = rows[size:]
+	}
+
+	return append(chunks, rows)
+}
+
+// setsKey reports whether any of the rows sets a primary key column.
+func setsKey(cmd ex.Command) bool {
+	for _, row := range cmd.RowsConfig {
+		for _, column := range rowsKey(cmd) {
+			if _, ok := row[column]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (e *executor) rowsWhere(cmd ex.Command, ids []any) ex.Where {
+
+	key := rowsKey(cmd)
+
+	var wheres []ex.Where
+	for _, row := range cmd.RowsConfig {
+		where := ex.Where{}
+		for _, column := range key {
+			if value, ok := row[column]; ok {
+				where[column] = value
+			}
+		}
+		if len(where) != len(key) {
+			wheres = nil
+			break
+		}
+		wheres = append(wheres, where)
+	}
+
+	switch {
+	case len(wheres) > 0 && len(key) == 1:
+		var values []any
+		for _, where := range wheres {
+			values = append(values, where[key[0]])
+		}
+		return ex.Where{key[0]: ex.In(values...)}
+
+	case len(wheres) > 0:
+		return ex.Or(wheres...)
+
+	case len(key) == 1 && len(ids) == len(cmd.RowsConfig) && !setsKey(cmd):
+		return ex.Where{key[0]: ex.In(ids...)}
+
+	default:
+		return nil
+	}
+}
+
+func (e *executor) queryAppend(ctx context.Context, tx Tx, stmt ex.Statement, data any) error {
+
+	rows, err := e.queryContext(ctx, tx, stmt)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	v := reflect.ValueOf(data)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Slice {
+		return e.Scanner.Scan(rows, data)
+	}
+
+	chunk := reflect.New(v.Elem().Type())
+	if err := e.Scanner.Scan(rows, chunk.Interface()); err != nil {
+		return err
+	}
+
+	v.Elem().Set(reflect.AppendSlice(v.Elem(), chunk.Elem()))
+	return nil
+}
+
+func resetSlice(data any) {
+	v := reflect.ValueOf(data)
+	if v.Kind() == reflect.Ptr && v.Elem().Kind() == reflect.Slice {
+		v.Elem().Set(reflect.MakeSlice(v.Elem().Type(), 0, 0))
+	}
+}
+
 func (e *executor) update(ctx context.Context, tx Tx, cmd ex.Command, cols map[string]string, data any) error {
 
+	if data != nil && e.supportsReturning() {
+		return e.returning(ctx, tx, "update", cmd, cols, data)
+	}
+
 	stmt, err := e.Formatter.Format(cmd, cols)
 	if err != nil {
 		return err
@@ -333,11 +1029,124 @@ func (e *executor) update(ctx context.Context, tx Tx, cmd ex.Command, cols map[s
 	return nil
 }
 
+func (e *executor) returning(ctx context.Context, tx Tx, name string, cmd ex.Command, cols map[string]string, data any) error {
+
+	if len(cmd.ReturningConfig) == 0 {
+		cmd.ReturningConfig = ex.ReturningConfig{"*"}
+	}
+
+	stmt, err := e.Formatter.Format(cmd, cols)
+	if err != nil {
+		return err
+	}
+
+	span, spanCtx := e.Tracer.StartSpan(ctx, name)
+	defer span.Finish()
+
+	rows, err := e.queryContext(spanCtx, tx, stmt)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	// The statement returns a row for each row it affected
+	var counted *countedRows
+	if _, ok := ctx.Value(affectedKey{}).(*int64); ok {
+		counted = &countedRows{Rows: rows}
+		rows = counted
+	}
+
+	if err := e.Scanner.Scan(rows, data); err != nil {
+		return err
+	}
+
+	if counted == nil {
+		return nil
+	}
+
+	// A single destination is scanned from the first row, so the rest are
+	// still counted
+	for counted.Next() {
+	}
+
+	if err := counted.Err(); err != nil {
+		return err
+	}
+
+	addAffected(ctx, counted.count)
+	return nil
+}
+
+// countedRows counts the rows read from the underlying rows.
+type countedRows struct {
+	Rows
+	count int64
+}
+
+func (r *countedRows) Next() bool {
+
+	if !r.Rows.Next() {
+		return false
+	}
+
+	r.count++
+	return true
+}
+
+func (e *executor) supportsReturning() bool {
+	f, ok := e.Formatter.(ReturningFormatter)
+	return ok && f.SupportsReturning()
+}
+
+func (e *executor) load(ctx context.Context, tx Tx, load ex.Load, data any) error {
+
+	if len(load.Columns) == 0 {
+		return errors.New("missing load columns")
+	}
+
+	cols, err := e.getColumnTypes(ctx, tx, load.Resource)
+	if err != nil {
+		return err
+	}
+
+	// Validating the columns as insert values checks them the same way
+	values := ex.Values{}
+	for _, column := range load.Columns {
+		values[column] = nil
+	}
+
+	if err := e.Validator.Validate(ex.Insert(load.Resource, values), cols); err != nil {
+		return ex.NewError(ex.Invalid, fmt.Errorf("invalid load: %w", err))
+	}
+
+	span, spanCtx := e.Tracer.StartSpan(ctx, "load")
+	defer span.Finish()
+
+	if err := e.Loader.Load(spanCtx, tx, load); err != nil {
+		return err
+	}
+
+	if data != nil {
+		return e.Scanner.Scan(emptyRows{}, data)
+	}
+
+	return nil
+}
+
 func (e *executor) batch(ctx context.Context, tx Tx, batch ex.Batch, data any) error {
 
 	span, spanCtx := e.Tracer.StartSpan(ctx, "batch")
 	defer span.Finish()
 
+	if batch.Partial {
+		return e.partial(spanCtx, tx, batch, data)
+	}
+
+	if results, ok := data.(ex.Results); ok {
+		return e.results(spanCtx, tx, batch, results)
+	}
+
 	var indexOfLastNonInstruction int
 	for i, r := range batch.Requests {
 		if _, ok := r.(ex.Instruction); ok {
@@ -362,6 +1171,93 @@ func (e *executor) batch(ctx context.Context, tx Tx, batch ex.Batch, data any) e
 	return nil
 }
 
+func (e *executor) results(ctx context.Context, tx Tx, batch ex.Batch, results ex.Results) error {
+
+	var i int
+	for _, r := range batch.Requests {
+		if _, ok := r.(ex.Instruction); ok {
+			if err := e.executeTx(ctx, tx, r, nil); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var data any
+		if i < len(results) {
+			data = results[i]
+		}
+
+		if err := e.executeTx(ctx, tx, r, data); err != nil {
+			return err
+		}
+		i++
+	}
+
+	return nil
+}
+
+type affectedKey struct{}
+
+// partial runs each request of the batch in a savepoint, so a failure only
+// rolls back its own request. Instructions run outside of the savepoints
+// and aren't reported.
+func (e *executor) partial(ctx context.Context, tx Tx, batch ex.Batch, data any) error {
+
+	var results []ex.BatchResult
+
+	for _, r := range batch.Requests {
+		if _, ok := r.(ex.Instruction); ok {
+			if err := e.executeTx(ctx, tx, r, nil); err != nil {
+				return err
+			}
+			continue
+		}
+
+		result := ex.BatchResult{Index: len(results)}
+		savepoint := fmt.Sprintf("ex_%d", result.Index)
+
+		if _, err := tx.ExecContext(ctx, "SAVEPOINT "+savepoint); err != nil {
+			return err
+		}
+
+		affectedCtx := context.WithValue(ctx, affectedKey{}, &result.RowsAffected)
+
+		if err := e.executeTx(affectedCtx, tx, r, nil); err != nil {
+			// A deadlock has already rolled back the whole transaction,
+			// which leaves no savepoint to return to
+			if _, rerr := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+savepoint); rerr != nil {
+				return err
+			}
+			result.Error = err.Error()
+			result.RowsAffected = 0
+
+		} else if _, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT "+savepoint); err != nil {
+			return err
+
+		} else {
+			addAffected(ctx, result.RowsAffected)
+		}
+
+		results = append(results, result)
+	}
+
+	switch d := data.(type) {
+	case nil:
+		return nil
+
+	case *[]ex.BatchResult:
+		*d = results
+		return nil
+
+	default:
+		b, err := json.Marshal(results)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(b, data)
+	}
+}
+
 func (e *executor) stmt(ctx context.Context, tx Tx, stmt ex.Statement, data any) error {
 
 	span, spanCtx := e.Tracer.StartSpan(ctx, "stmt")
@@ -395,10 +1291,66 @@ func (e *executor) execContext(ctx context.Context, tx Tx, stmt ex.Statement) (R
 	span, spanCtx := e.Tracer.StartSpan(ctx, "exec", ex.SpanTag{Key: "stmt", Value: stmt.Stmt})
 	defer span.Finish()
 
-	return tx.ExecContext(spanCtx, stmt.Stmt, stmt.Args...)
+	res, err := tx.ExecContext(spanCtx, stmt.Stmt, stmt.Args...)
+	if err != nil {
+		return nil, err
+	}
+
+	if affected, ok := ctx.Value(affectedKey{}).(*int64); ok {
+		if n, err := res.RowsAffected(); err == nil {
+			*affected += n
+		}
+	}
+
+	return res, nil
+}
+
+func addAffected(ctx context.Context, n int64) {
+	if affected, ok := ctx.Value(affectedKey{}).(*int64); ok {
+		*affected += n
+	}
+}
+
+func (e *executor) getCommandColumnTypes(ctx context.Context, tx Tx, cmd ex.Command) (map[string]string, error) {
+
+	cols, err := e.getColumnTypes(ctx, tx, cmd.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cmd.JoinConfig) == 0 {
+		return cols, nil
+	}
+
+	// Joined commands can reference columns qualified by their table name
+	columns := map[string]string{}
+	for name, t := range cols {
+		columns[name] = t
+		columns[cmd.Resource+"."+name] = t
+	}
+
+	for _, join := range cmd.JoinConfig {
+		joinCols, err := e.getColumnTypes(ctx, tx, join.Resource)
+		if err != nil {
+			return nil, err
+		}
+
+		for name, t := range joinCols {
+			columns[join.Resource+"."+name] = t
+		}
+	}
+
+	return columns, nil
 }
 
 func (e *executor) getColumnTypes(ctx context.Context, tx Tx, tableName string) (map[string]string, error) {
+
+	// The name is formatted into the query, so it's validated before the
+	// rest of the command can be
+	if err := e.Validator.Validate(ex.Query(tableName), nil); err != nil {
+		return nil, ex.NewError(ex.Invalid, err)
+	}
+
 	e.Lock()
 	defer e.Unlock()
 
@@ -411,9 +1363,10 @@ func (e *executor) getColumnTypes(ctx context.Context, tx Tx, tableName string)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
+	// The rows are closed first, since a connection runs one query at a time
 	columnTypes, err := rows.ColumnTypes()
+	rows.Close()
 	if err != nil {
 		return nil, err
 	}
@@ -424,15 +1377,61 @@ func (e *executor) getColumnTypes(ctx context.Context, tx Tx, tableName string)
 	}
 
 	if len(columns) > 0 {
+		primaryKey, err := e.queryPrimaryKey(ctx, tx, tableName)
+		if err != nil {
+			return nil, err
+		}
+
 		e.TypeCache[tableName] = TableEntry{
-			Types:     columns,
-			UpdatedAt: time.Now(),
+			Types:      columns,
+			PrimaryKey: primaryKey,
+			UpdatedAt:  time.Now(),
 		}
 	}
 
 	return columns, nil
 }
 
+func (e *executor) getPrimaryKey(tableName string) []string {
+	e.Lock()
+	defer e.Unlock()
+
+	if key, ok := e.PrimaryKeys[tableName]; ok {
+		return key
+	}
+
+	return e.TypeCache[tableName].PrimaryKey
+}
+
+func (e *executor) queryPrimaryKey(ctx context.Context, tx Tx, tableName string) ([]string, error) {
+
+	f, ok := e.Formatter.(PrimaryKeyFormatter)
+	if !ok {
+		return nil, nil
+	}
+
+	if _, ok := e.PrimaryKeys[tableName]; ok {
+		return nil, nil
+	}
+
+	rows, err := e.queryContext(ctx, tx, f.FormatPrimaryKey(tableName))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var key []string
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return nil, err
+		}
+		key = append(key, column)
+	}
+
+	return key, rows.Err()
+}
+
 type noopSpan struct{}
 
 func (s noopSpan) Finish() {}
@@ -453,8 +1452,9 @@ func (t noopTracer) ExtractSpan(r *http.Request, name string) (ex.Span, context.
 type TypeCache map[string]TableEntry
 
 type TableEntry struct {
-	Types     map[string]string
-	UpdatedAt time.Time
+	Types      map[string]string
+	PrimaryKey []string
+	UpdatedAt  time.Time
 }
 
 func (e TableEntry) IsValid(duration time.Duration) bool {
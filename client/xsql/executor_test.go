@@ -2,6 +2,7 @@ package xsql_test
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"net/http"
@@ -57,6 +58,8 @@ var _ = Describe("Executor", func() {
 		mockTypeRows = mocks.NewMockRows(mockCtrl)
 		mockResult = mocks.NewMockResult(mockCtrl)
 
+		mockValidator.EXPECT().Validate(ex.Query("resources"), gomock.Nil()).Return(nil).AnyTimes()
+
 		columnTypes = []xsql.ColumnType{
 			columnType{
 				name:             "id",
@@ -96,7 +99,7 @@ var _ = Describe("Executor", func() {
 
 		Context("when beginning a tx fails", func() {
 			BeforeEach(func() {
-				mockConnection.EXPECT().Begin().Return(nil, errors.New("nope"))
+				mockConnection.EXPECT().BeginTx(gomock.Any(), gomock.Any()).Return(nil, errors.New("nope"))
 			})
 
 			It("errors", func() {
@@ -107,7 +110,7 @@ var _ = Describe("Executor", func() {
 		Context("when beginning a tx succeeds", func() {
 			BeforeEach(func() {
 				mockTx.EXPECT().Rollback().Return(nil)
-				mockConnection.EXPECT().Begin().Return(mockTx, nil)
+				mockConnection.EXPECT().BeginTx(gomock.Any(), gomock.Any()).Return(mockTx, nil)
 			})
 
 			Context("when querying column types succeeeds", func() {
@@ -125,6 +128,10 @@ var _ = Describe("Executor", func() {
 					It("errors", func() {
 						Expect(err).To(HaveOccurred())
 					})
+
+					It("classifies the error as invalid", func() {
+						Expect(ex.CodeOf(err)).To(Equal(ex.Invalid))
+					})
 				})
 
 				Context("when validating the request succeeds", func() {
@@ -208,6 +215,804 @@ var _ = Describe("Executor", func() {
 		})
 	})
 
+	Describe("QUERY with joins", func() {
+		var mockJoinTypeRows *mocks.MockRows
+
+		BeforeEach(func() {
+			req = ex.Query("resources", ex.Join("owners", "owners.id = resources.owner_id"))
+
+			mockJoinTypeRows = mocks.NewMockRows(mockCtrl)
+
+			mockValidator.EXPECT().Validate(ex.Query("owners"), gomock.Nil()).Return(nil).AnyTimes()
+			mockTx.EXPECT().Rollback().Return(nil)
+			mockConnection.EXPECT().BeginTx(gomock.Any(), gomock.Any()).Return(mockTx, nil)
+			mockTx.EXPECT().QueryContext(ctx, "SELECT * FROM resources LIMIT 0").Return(mockTypeRows, nil)
+			mockTypeRows.EXPECT().ColumnTypes().Return(columnTypes, nil)
+			mockTypeRows.EXPECT().Close().Return(nil)
+		})
+
+		Context("when the joined resource is invalid", func() {
+			BeforeEach(func() {
+				req = ex.Query("resources", ex.Join("t;COMMIT;DELETE/**/FROM/**/x;--", "t.id = resources.id"))
+
+				mockValidator.EXPECT().Validate(ex.Query("t;COMMIT;DELETE/**/FROM/**/x;--"), gomock.Nil()).Return(errors.New("nope"))
+			})
+
+			It("errors before querying the joined resource", func() {
+				Expect(ex.CodeOf(err)).To(Equal(ex.Invalid))
+			})
+		})
+
+		Context("when querying the joined column types fails", func() {
+			BeforeEach(func() {
+				mockTx.EXPECT().QueryContext(ctx, "SELECT * FROM owners LIMIT 0").Return(nil, errors.New("nope"))
+			})
+
+			It("errors", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		Context("when querying the joined column types succeeds", func() {
+			BeforeEach(func() {
+				mockTx.EXPECT().QueryContext(ctx, "SELECT * FROM owners LIMIT 0").Return(mockJoinTypeRows, nil)
+				mockJoinTypeRows.EXPECT().ColumnTypes().Return(columnTypes, nil)
+				mockJoinTypeRows.EXPECT().Close().Return(nil)
+
+				cols := map[string]string{
+					"id":             "INTEGER",
+					"name":           "VARCHAR(160)",
+					"resources.id":   "INTEGER",
+					"resources.name": "VARCHAR(160)",
+					"owners.id":      "INTEGER",
+					"owners.name":    "VARCHAR(160)",
+				}
+
+				mockValidator.EXPECT().Validate(req, cols).Return(nil)
+				mockFormatter.EXPECT().Format(req, cols).Return(ex.Statement{Stmt: "some-stmt"}, nil)
+				mockTx.EXPECT().QueryContext(ctx, "some-stmt").Return(mockRows, nil)
+				mockRows.EXPECT().Close().Return(nil)
+				mockScanner.EXPECT().Scan(mockRows, data).Return(nil)
+				mockTx.EXPECT().Commit().Return(nil)
+			})
+
+			It("validates and formats with the qualified column types", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("RETURNING", func() {
+		BeforeEach(func() {
+			data = &[]map[string]any{}
+
+			executor = xsql.NewExecutor(newLogger(),
+				xsql.WithConnection(mockConnection),
+				xsql.WithFormatter(returningFormatter{mockFormatter}),
+				xsql.WithScanner(mockScanner),
+				xsql.WithTracer(noopTracer{}),
+				xsql.WithTypeCacheDuration(0),
+				xsql.WithValidator(mockValidator),
+			)
+
+			mockTx.EXPECT().Rollback().Return(nil)
+			mockConnection.EXPECT().BeginTx(gomock.Any(), gomock.Any()).Return(mockTx, nil)
+			mockTx.EXPECT().QueryContext(ctx, "SELECT * FROM resources LIMIT 0").Return(mockTypeRows, nil)
+			mockTypeRows.EXPECT().ColumnTypes().Return(columnTypes, nil)
+			mockTypeRows.EXPECT().Close().Return(nil)
+			mockValidator.EXPECT().Validate(gomock.Any(), gomock.Any()).Return(nil)
+		})
+
+		AfterEach(func() {
+			data = nil
+		})
+
+		Context("when inserting", func() {
+			BeforeEach(func() {
+				req = ex.Insert("resources", ex.Values{"name": "some-name"})
+
+				mockFormatter.EXPECT().Format(ex.Insert("resources", ex.Values{"name": "some-name"}, ex.Returning()), gomock.Any()).Return(ex.Statement{
+					Stmt: "some-stmt",
+					Args: []any{"some-arg"},
+				}, nil)
+			})
+
+			Context("when executing the request fails", func() {
+				BeforeEach(func() {
+					mockTx.EXPECT().QueryContext(ctx, "some-stmt", "some-arg").Return(nil, errors.New("nope"))
+				})
+
+				It("errors", func() {
+					Expect(err).To(HaveOccurred())
+				})
+			})
+
+			Context("when executing the request succeeds", func() {
+				BeforeEach(func() {
+					mockTx.EXPECT().QueryContext(ctx, "some-stmt", "some-arg").Return(mockRows, nil)
+					mockRows.EXPECT().Close().Return(nil)
+					mockScanner.EXPECT().Scan(mockRows, data).Return(nil)
+					mockTx.EXPECT().Commit().Return(nil)
+				})
+
+				It("scans the returned rows without querying again", func() {
+					Expect(err).NotTo(HaveOccurred())
+				})
+			})
+		})
+
+		Context("when updating with chosen columns", func() {
+			BeforeEach(func() {
+				req = ex.Update("resources", ex.Values{"name": "some-name"}, ex.Where{"name": "other-name"}, ex.Returning("id"))
+
+				mockFormatter.EXPECT().Format(req, gomock.Any()).Return(ex.Statement{Stmt: "some-stmt"}, nil)
+				mockTx.EXPECT().QueryContext(ctx, "some-stmt").Return(mockRows, nil)
+				mockRows.EXPECT().Close().Return(nil)
+				mockScanner.EXPECT().Scan(mockRows, data).Return(nil)
+				mockTx.EXPECT().Commit().Return(nil)
+			})
+
+			It("scans the returned rows without querying again", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("when deleting", func() {
+			BeforeEach(func() {
+				req = ex.Delete("resources", ex.Where{"name": "some-name"})
+
+				mockFormatter.EXPECT().Format(ex.Delete("resources", ex.Where{"name": "some-name"}, ex.Returning()), gomock.Any()).Return(ex.Statement{Stmt: "some-stmt"}, nil)
+				mockTx.EXPECT().QueryContext(ctx, "some-stmt").Return(mockRows, nil)
+				mockRows.EXPECT().Close().Return(nil)
+				mockScanner.EXPECT().Scan(mockRows, data).Return(nil)
+				mockTx.EXPECT().Commit().Return(nil)
+			})
+
+			It("scans the returned rows without querying first", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("RETURNING ROWS AFFECTED", func() {
+		var meta *ex.Meta
+
+		BeforeEach(func() {
+			meta = &ex.Meta{}
+			ctx = ex.WithMeta(ctx, meta)
+
+			req = ex.Update("resources", ex.Values{"name": "some-name"}, ex.Where{"name": "other-name"}, ex.Returning("id"))
+
+			executor = xsql.NewExecutor(newLogger(),
+				xsql.WithConnection(mockConnection),
+				xsql.WithFormatter(returningFormatter{mockFormatter}),
+				xsql.WithScanner(mockScanner),
+				xsql.WithTracer(noopTracer{}),
+				xsql.WithTypeCacheDuration(0),
+				xsql.WithValidator(mockValidator),
+			)
+
+			mockTx.EXPECT().Rollback().Return(nil)
+			mockConnection.EXPECT().BeginTx(gomock.Any(), gomock.Any()).Return(mockTx, nil)
+			mockTx.EXPECT().QueryContext(gomock.Any(), "SELECT * FROM resources LIMIT 0").Return(mockTypeRows, nil)
+			mockTypeRows.EXPECT().ColumnTypes().Return(columnTypes, nil)
+			mockTypeRows.EXPECT().Close().Return(nil)
+			mockValidator.EXPECT().Validate(gomock.Any(), gomock.Any()).Return(nil)
+			mockFormatter.EXPECT().Format(req, gomock.Any()).Return(ex.Statement{Stmt: "some-stmt"}, nil)
+			mockTx.EXPECT().QueryContext(gomock.Any(), "some-stmt").Return(mockRows, nil)
+			mockRows.EXPECT().Close().Return(nil)
+		})
+
+		AfterEach(func() {
+			data = nil
+		})
+
+		Context("when the rows are scanned into a single destination", func() {
+			BeforeEach(func() {
+				data = &map[string]any{}
+
+				mockScanner.EXPECT().Scan(gomock.Any(), data).DoAndReturn(func(rows xsql.Rows, data any) error {
+					if rows.Next() {
+						return rows.Scan()
+					}
+					return nil
+				})
+				mockRows.EXPECT().Scan().Return(nil)
+				mockRows.EXPECT().Next().Return(true).Times(2)
+				mockRows.EXPECT().Next().Return(false)
+				mockRows.EXPECT().Err().Return(nil)
+				mockTx.EXPECT().Commit().Return(nil)
+			})
+
+			It("reports every row returned", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(meta.RowsAffected).NotTo(BeNil())
+				Expect(*meta.RowsAffected).To(Equal(int64(2)))
+			})
+		})
+
+		Context("when the rows are scanned into a slice", func() {
+			BeforeEach(func() {
+				data = &[]map[string]any{}
+
+				mockScanner.EXPECT().Scan(gomock.Any(), data).DoAndReturn(func(rows xsql.Rows, data any) error {
+					for rows.Next() {
+						if err := rows.Scan(); err != nil {
+							return err
+						}
+					}
+					return nil
+				})
+				mockRows.EXPECT().Scan().Return(nil).Times(3)
+				mockRows.EXPECT().Next().Return(true).Times(3)
+				mockRows.EXPECT().Next().Return(false).Times(2)
+				mockRows.EXPECT().Err().Return(nil)
+				mockTx.EXPECT().Commit().Return(nil)
+			})
+
+			It("reports every row returned", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(*meta.RowsAffected).To(Equal(int64(3)))
+			})
+		})
+
+		Context("when reading the rest of the rows fails", func() {
+			BeforeEach(func() {
+				data = &map[string]any{}
+
+				mockScanner.EXPECT().Scan(gomock.Any(), data).Return(nil)
+				mockRows.EXPECT().Next().Return(false)
+				mockRows.EXPECT().Err().Return(errors.New("nope"))
+			})
+
+			It("errors", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("CURSOR", func() {
+		var meta *ex.Meta
+
+		BeforeEach(func() {
+			meta = &ex.Meta{}
+			ctx = ex.WithMeta(context.Background(), meta)
+			data = &[]map[string]any{}
+
+			mockTx.EXPECT().Rollback().Return(nil)
+			mockConnection.EXPECT().BeginTx(gomock.Any(), gomock.Any()).Return(mockTx, nil)
+			mockTx.EXPECT().QueryContext(ctx, "SELECT * FROM resources LIMIT 0").Return(mockTypeRows, nil)
+			mockTypeRows.EXPECT().ColumnTypes().Return(columnTypes, nil)
+			mockTypeRows.EXPECT().Close().Return(nil)
+			mockValidator.EXPECT().Validate(gomock.Any(), gomock.Any()).Return(nil)
+			mockFormatter.EXPECT().Format(gomock.Any(), gomock.Any()).Return(ex.Statement{Stmt: "some-stmt"}, nil)
+			mockTx.EXPECT().QueryContext(ctx, "some-stmt").Return(mockRows, nil)
+			mockRows.EXPECT().Close().Return(nil)
+			mockRows.EXPECT().ColumnTypes().Return(columnTypes, nil)
+
+			ids := []int64{3, 2}
+			names := []string{"c", "b"}
+			mockRows.EXPECT().Scan(gomock.Any(), gomock.Any()).DoAndReturn(func(dest ...any) error {
+				*dest[0].(*sql.NullInt64) = sql.NullInt64{Int64: ids[0], Valid: true}
+				*dest[1].(*sql.NullString) = sql.NullString{String: names[0], Valid: true}
+				ids, names = ids[1:], names[1:]
+				return nil
+			}).Times(2)
+
+			// The fields are named differently from the columns they're
+			// scanned from
+			mockScanner.EXPECT().Scan(gomock.Any(), data).DoAndReturn(func(rows xsql.Rows, data any) error {
+				for rows.Next() {
+					var id sql.NullInt64
+					var name sql.NullString
+					if err := rows.Scan(&id, &name); err != nil {
+						return err
+					}
+					res := data.(*[]map[string]any)
+					*res = append(*res, map[string]any{"resource_id": id.Int64, "resource_name": name.String})
+				}
+				return nil
+			})
+			mockRows.EXPECT().Next().Return(true).Times(2)
+			mockRows.EXPECT().Next().Return(false)
+			mockTx.EXPECT().Commit().Return(nil)
+		})
+
+		AfterEach(func() {
+			ctx = context.Background()
+			data = nil
+		})
+
+		Context("when the page is full", func() {
+			BeforeEach(func() {
+				req = ex.Query("resources", ex.Order("name DESC", "id DESC"), ex.Limit(2))
+			})
+
+			It("reports the cursor of the last row", func() {
+				Expect(err).NotTo(HaveOccurred())
+
+				cursor, _ := ex.EncodeCursor("b", 2)
+				Expect(meta.NextCursor).To(Equal(cursor))
+			})
+
+			It("doesn't report a cursor to turn back", func() {
+				Expect(meta.PrevCursor).To(BeEmpty())
+			})
+		})
+
+		Context("when paging forward", func() {
+			BeforeEach(func() {
+				cursor, _ := ex.EncodeCursor("d", 4)
+				req = ex.Query("resources", ex.Order("name DESC", "id DESC"), ex.After(cursor), ex.Limit(2))
+			})
+
+			It("reports the cursor of the last row", func() {
+				Expect(err).NotTo(HaveOccurred())
+
+				cursor, _ := ex.EncodeCursor("b", 2)
+				Expect(meta.NextCursor).To(Equal(cursor))
+			})
+
+			It("reports the cursor of the first row to turn back", func() {
+				cursor, _ := ex.EncodeCursor("c", 3)
+				Expect(meta.PrevCursor).To(Equal(cursor))
+			})
+		})
+
+		Context("when the page is not full", func() {
+			BeforeEach(func() {
+				req = ex.Query("resources", ex.Order("name DESC", "id DESC"), ex.Limit(3))
+			})
+
+			It("doesn't report a cursor", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(meta.NextCursor).To(BeEmpty())
+			})
+		})
+
+		Context("when paging backward", func() {
+			BeforeEach(func() {
+				cursor, _ := ex.EncodeCursor(1)
+				req = ex.Query("resources", ex.Order("id"), ex.Before(cursor), ex.Limit(2))
+			})
+
+			It("restores the requested order", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(*data.(*[]map[string]any)).To(Equal([]map[string]any{
+					{"resource_id": int64(2), "resource_name": "b"},
+					{"resource_id": int64(3), "resource_name": "c"},
+				}))
+			})
+
+			It("reports the cursor of the first row", func() {
+				cursor, _ := ex.EncodeCursor(2)
+				Expect(meta.NextCursor).To(Equal(cursor))
+			})
+
+			It("reports the cursor of the last row to turn back", func() {
+				cursor, _ := ex.EncodeCursor(3)
+				Expect(meta.PrevCursor).To(Equal(cursor))
+			})
+		})
+	})
+
+	Describe("TOTAL", func() {
+		var meta *ex.Meta
+		var mockCountRows *mocks.MockRows
+
+		BeforeEach(func() {
+			meta = &ex.Meta{}
+			ctx = ex.WithMeta(context.Background(), meta)
+			data = &[]map[string]any{}
+			mockCountRows = mocks.NewMockRows(mockCtrl)
+
+			req = ex.Query("resources", ex.Where{"name": "some-name"}, ex.Order("id"), ex.Limit(10), ex.Offset(20), ex.WithTotal())
+
+			mockTx.EXPECT().Rollback().Return(nil)
+			mockConnection.EXPECT().BeginTx(gomock.Any(), gomock.Any()).Return(mockTx, nil)
+			mockTx.EXPECT().QueryContext(ctx, "SELECT * FROM resources LIMIT 0").Return(mockTypeRows, nil)
+			mockTypeRows.EXPECT().ColumnTypes().Return(columnTypes, nil)
+			mockTypeRows.EXPECT().Close().Return(nil)
+			mockValidator.EXPECT().Validate(gomock.Any(), gomock.Any()).Return(nil)
+			mockFormatter.EXPECT().Format(req, gomock.Any()).Return(ex.Statement{Stmt: "some-stmt"}, nil)
+			mockTx.EXPECT().QueryContext(ctx, "some-stmt").Return(mockRows, nil)
+			mockRows.EXPECT().Close().Return(nil)
+			mockScanner.EXPECT().Scan(gomock.Any(), data).Return(nil)
+		})
+
+		AfterEach(func() {
+			ctx = context.Background()
+			data = nil
+		})
+
+		Context("when counting succeeds", func() {
+			BeforeEach(func() {
+				mockFormatter.EXPECT().Format(ex.Query("resources", ex.Where{"name": "some-name"}, ex.Columns("1")), gomock.Any()).Return(ex.Statement{Stmt: "some-count-stmt", Args: []any{"some-name"}}, nil)
+				mockTx.EXPECT().QueryContext(ctx, "SELECT COUNT(*) FROM (some-count-stmt) AS counted", "some-name").Return(mockCountRows, nil)
+				mockCountRows.EXPECT().Next().Return(true)
+				mockCountRows.EXPECT().Scan(gomock.Any()).DoAndReturn(func(dest ...any) error {
+					*dest[0].(*int64) = 42
+					return nil
+				})
+				mockCountRows.EXPECT().Err().Return(nil)
+				mockCountRows.EXPECT().Close().Return(nil)
+				mockTx.EXPECT().Commit().Return(nil)
+			})
+
+			It("reports the total", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(meta.Total).NotTo(BeNil())
+				Expect(*meta.Total).To(Equal(int64(42)))
+			})
+		})
+
+		Context("when counting fails", func() {
+			BeforeEach(func() {
+				mockFormatter.EXPECT().Format(gomock.Any(), gomock.Any()).Return(ex.Statement{Stmt: "some-count-stmt"}, nil)
+				mockTx.EXPECT().QueryContext(ctx, "SELECT COUNT(*) FROM (some-count-stmt) AS counted").Return(nil, errors.New("nope"))
+			})
+
+			It("errors", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("PRIMARY KEY", func() {
+		BeforeEach(func() {
+			mockTx.EXPECT().Rollback().Return(nil)
+			mockConnection.EXPECT().BeginTx(gomock.Any(), gomock.Any()).Return(mockTx, nil)
+			mockTx.EXPECT().QueryContext(ctx, "SELECT * FROM resources LIMIT 0").Return(mockTypeRows, nil)
+			mockTypeRows.EXPECT().ColumnTypes().Return(columnTypes, nil)
+			mockTypeRows.EXPECT().Close().Return(nil)
+			mockValidator.EXPECT().Validate(gomock.Any(), gomock.Any()).Return(nil)
+		})
+
+		AfterEach(func() {
+			data = nil
+		})
+
+		Context("when the primary key is declared for the resource", func() {
+			BeforeEach(func() {
+				data = &[]map[string]any{}
+
+				executor = xsql.NewExecutor(newLogger(),
+					xsql.WithConnection(mockConnection),
+					xsql.WithFormatter(mockFormatter),
+					xsql.WithScanner(mockScanner),
+					xsql.WithTracer(noopTracer{}),
+					xsql.WithTypeCacheDuration(0),
+					xsql.WithValidator(mockValidator),
+					xsql.WithPrimaryKey("resources", "name"),
+				)
+
+				req = ex.Insert("resources", ex.Values{"name": "some-name"})
+
+				mockFormatter.EXPECT().Format(ex.Insert("resources", ex.Values{"name": "some-name"}, ex.PrimaryKey("name")), gomock.Any()).Return(ex.Statement{Stmt: "some-stmt"}, nil)
+				mockTx.EXPECT().ExecContext(ctx, "some-stmt").Return(mockResult, nil)
+				mockFormatter.EXPECT().Format(ex.Query("resources", ex.Where{"name": "some-name"}), gomock.Any()).Return(ex.Statement{Stmt: "some-query"}, nil)
+				mockTx.EXPECT().QueryContext(ctx, "some-query").Return(mockRows, nil)
+				mockRows.EXPECT().Close().Return(nil)
+				mockScanner.EXPECT().Scan(mockRows, data).Return(nil)
+				mockTx.EXPECT().Commit().Return(nil)
+			})
+
+			It("queries the inserted row by its primary key", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("when the formatter can discover the primary key", func() {
+			var mockKeyRows *mocks.MockRows
+
+			BeforeEach(func() {
+				mockKeyRows = mocks.NewMockRows(mockCtrl)
+
+				executor = xsql.NewExecutor(newLogger(),
+					xsql.WithConnection(mockConnection),
+					xsql.WithFormatter(primaryKeyFormatter{mockFormatter}),
+					xsql.WithScanner(mockScanner),
+					xsql.WithTracer(noopTracer{}),
+					xsql.WithTypeCacheDuration(0),
+					xsql.WithValidator(mockValidator),
+				)
+
+				req = ex.Query("resources", ex.PartitionBy("name"))
+
+				mockTx.EXPECT().QueryContext(ctx, "some-key-stmt", "resources").Return(mockKeyRows, nil)
+				gomock.InOrder(
+					mockKeyRows.EXPECT().Next().Return(true),
+					mockKeyRows.EXPECT().Scan(gomock.Any()).DoAndReturn(func(dest ...any) error {
+						*(dest[0].(*string)) = "uuid"
+						return nil
+					}),
+					mockKeyRows.EXPECT().Next().Return(false),
+				)
+				mockKeyRows.EXPECT().Err().Return(nil)
+				mockKeyRows.EXPECT().Close().Return(nil)
+
+				mockFormatter.EXPECT().Format(ex.Query("resources", ex.PartitionBy("name"), ex.PrimaryKey("uuid")), gomock.Any()).Return(ex.Statement{Stmt: "some-query"}, nil)
+				mockTx.EXPECT().QueryContext(ctx, "some-query").Return(mockRows, nil)
+				mockRows.EXPECT().Close().Return(nil)
+				mockScanner.EXPECT().Scan(mockRows, data).Return(nil)
+				mockTx.EXPECT().Commit().Return(nil)
+			})
+
+			It("formats the command with the discovered primary key", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("INSERT with rows", func() {
+		BeforeEach(func() {
+			mockTx.EXPECT().Rollback().Return(nil)
+			mockConnection.EXPECT().BeginTx(gomock.Any(), gomock.Any()).Return(mockTx, nil)
+			mockTx.EXPECT().QueryContext(ctx, "SELECT * FROM resources LIMIT 0").Return(mockTypeRows, nil)
+			mockTypeRows.EXPECT().ColumnTypes().Return(columnTypes, nil)
+			mockTypeRows.EXPECT().Close().Return(nil)
+			mockValidator.EXPECT().Validate(gomock.Any(), gomock.Any()).Return(nil)
+		})
+
+		AfterEach(func() {
+			data = nil
+		})
+
+		Context("when the rows exceed the placeholder limit", func() {
+			BeforeEach(func() {
+				executor = xsql.NewExecutor(newLogger(),
+					xsql.WithConnection(mockConnection),
+					xsql.WithFormatter(bulkFormatter{mockFormatter}),
+					xsql.WithScanner(mockScanner),
+					xsql.WithTracer(noopTracer{}),
+					xsql.WithTypeCacheDuration(0),
+					xsql.WithValidator(mockValidator),
+				)
+
+				req = ex.Insert("resources", ex.Rows(
+					ex.Values{"name": "name1"},
+					ex.Values{"name": "name2"},
+					ex.Values{"name": "name3"},
+				))
+
+				gomock.InOrder(
+					mockFormatter.EXPECT().Format(ex.Insert("resources", ex.Rows(ex.Values{"name": "name1"}, ex.Values{"name": "name2"})), gomock.Any()).Return(ex.Statement{Stmt: "some-stmt-1"}, nil),
+					mockTx.EXPECT().ExecContext(ctx, "some-stmt-1").Return(mockResult, nil),
+					mockFormatter.EXPECT().Format(ex.Insert("resources", ex.Rows(ex.Values{"name": "name3"})), gomock.Any()).Return(ex.Statement{Stmt: "some-stmt-2"}, nil),
+					mockTx.EXPECT().ExecContext(ctx, "some-stmt-2").Return(mockResult, nil),
+				)
+				mockTx.EXPECT().Commit().Return(nil)
+			})
+
+			It("inserts the rows in chunks", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("when the data result is NOT nil", func() {
+			BeforeEach(func() {
+				data = &[]map[string]any{}
+
+				req = ex.Insert("resources", ex.Rows(ex.Values{"name": "name1"}, ex.Values{"name": "name2"}))
+
+				mockFormatter.EXPECT().Format(req, gomock.Any()).Return(ex.Statement{Stmt: "some-stmt"}, nil)
+				mockTx.EXPECT().ExecContext(ctx, "some-stmt").Return(mockResult, nil)
+				mockResult.EXPECT().LastInsertId().Return(int64(10), nil)
+				mockFormatter.EXPECT().Format(ex.Query("resources", ex.Where{"id": ex.In(int64(10), int64(11))}), gomock.Any()).Return(ex.Statement{Stmt: "some-query"}, nil)
+				mockTx.EXPECT().QueryContext(ctx, "some-query").Return(mockRows, nil)
+				mockRows.EXPECT().Close().Return(nil)
+				mockScanner.EXPECT().Scan(mockRows, data).Return(nil)
+				mockTx.EXPECT().Commit().Return(nil)
+			})
+
+			It("queries the inserted rows by their generated ids", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("when the rows are upserted without their primary key", func() {
+			BeforeEach(func() {
+				data = &[]map[string]any{}
+
+				req = ex.Insert("resources", ex.Rows(ex.Values{"name": "name1"}, ex.Values{"name": "name2"}), ex.OnConflictUpdate("name"))
+			})
+
+			It("errors without inserting the rows", func() {
+				Expect(ex.CodeOf(err)).To(Equal(ex.Invalid))
+			})
+		})
+
+		Context("when only some of the rows set their primary key", func() {
+			BeforeEach(func() {
+				data = &[]map[string]any{}
+
+				req = ex.Insert("resources", ex.Rows(ex.Values{"id": 100, "name": "name1"}, ex.Values{"name": "name2"}))
+			})
+
+			It("errors without inserting the rows", func() {
+				Expect(ex.CodeOf(err)).To(Equal(ex.Invalid))
+			})
+		})
+
+		Context("when the rows are upserted with their primary key", func() {
+			BeforeEach(func() {
+				data = &[]map[string]any{}
+
+				req = ex.Insert("resources", ex.Rows(ex.Values{"id": 1, "name": "name1"}, ex.Values{"id": 2, "name": "name2"}), ex.OnConflictIgnore("true"))
+
+				mockFormatter.EXPECT().Format(req, gomock.Any()).Return(ex.Statement{Stmt: "some-stmt"}, nil)
+				mockTx.EXPECT().ExecContext(ctx, "some-stmt").Return(mockResult, nil)
+				mockResult.EXPECT().LastInsertId().Return(int64(2), nil)
+				mockFormatter.EXPECT().Format(ex.Query("resources", ex.Where{"id": ex.In(1, 2)}), gomock.Any()).Return(ex.Statement{Stmt: "some-query"}, nil)
+				mockTx.EXPECT().QueryContext(ctx, "some-query").Return(mockRows, nil)
+				mockRows.EXPECT().Close().Return(nil)
+				mockScanner.EXPECT().Scan(mockRows, data).Return(nil)
+				mockTx.EXPECT().Commit().Return(nil)
+			})
+
+			It("queries the rows by their primary key", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("LOAD", func() {
+		var mockLoader *mocks.MockLoader
+
+		BeforeEach(func() {
+			mockLoader = mocks.NewMockLoader(mockCtrl)
+
+			executor = xsql.NewExecutor(newLogger(),
+				xsql.WithConnection(mockConnection),
+				xsql.WithFormatter(mockFormatter),
+				xsql.WithLoader(mockLoader),
+				xsql.WithScanner(mockScanner),
+				xsql.WithTracer(noopTracer{}),
+				xsql.WithTypeCacheDuration(0),
+				xsql.WithValidator(mockValidator),
+			)
+
+			mockTx.EXPECT().Rollback().Return(nil)
+			mockConnection.EXPECT().BeginTx(gomock.Any(), gomock.Any()).Return(mockTx, nil)
+		})
+
+		Context("when the load has no columns", func() {
+			BeforeEach(func() {
+				req = ex.BulkLoad("resources", nil, ex.IterateRows())
+			})
+
+			It("errors", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		Context("when the load has columns", func() {
+			BeforeEach(func() {
+				req = ex.BulkLoad("resources", []string{"id", "name"}, ex.IterateRows([]any{1, "some-name"}))
+
+				mockTx.EXPECT().QueryContext(ctx, "SELECT * FROM resources LIMIT 0").Return(mockTypeRows, nil)
+				mockTypeRows.EXPECT().ColumnTypes().Return(columnTypes, nil)
+				mockTypeRows.EXPECT().Close().Return(nil)
+			})
+
+			Context("when validating the columns fails", func() {
+				BeforeEach(func() {
+					mockValidator.EXPECT().Validate(ex.Insert("resources", ex.Values{"id": nil, "name": nil}), gomock.Any()).Return(errors.New("nope"))
+				})
+
+				It("errors", func() {
+					Expect(err).To(HaveOccurred())
+				})
+			})
+
+			Context("when validating the columns succeeds", func() {
+				BeforeEach(func() {
+					mockValidator.EXPECT().Validate(ex.Insert("resources", ex.Values{"id": nil, "name": nil}), gomock.Any()).Return(nil)
+				})
+
+				Context("when loading fails", func() {
+					BeforeEach(func() {
+						mockLoader.EXPECT().Load(ctx, mockTx, req).Return(errors.New("nope"))
+					})
+
+					It("errors without retrying", func() {
+						Expect(err).To(HaveOccurred())
+					})
+				})
+
+				Context("when loading succeeds", func() {
+					BeforeEach(func() {
+						mockLoader.EXPECT().Load(ctx, mockTx, req).Return(nil)
+						mockTx.EXPECT().Commit().Return(nil)
+					})
+
+					It("succeeds", func() {
+						Expect(err).NotTo(HaveOccurred())
+					})
+				})
+			})
+		})
+	})
+
+	Describe("ROWS AFFECTED", func() {
+		var meta *ex.Meta
+
+		BeforeEach(func() {
+			meta = &ex.Meta{}
+			ctx = ex.WithMeta(ctx, meta)
+
+			req = ex.Bulk(ex.Exec("some-stmt"), ex.Exec("other-stmt"))
+			data = nil
+
+			mockConnection.EXPECT().BeginTx(gomock.Any(), gomock.Any()).Return(mockTx, nil)
+			mockTx.EXPECT().Rollback().Return(nil)
+			mockTx.EXPECT().ExecContext(gomock.Any(), "some-stmt").Return(mockResult, nil)
+			mockTx.EXPECT().ExecContext(gomock.Any(), "other-stmt").Return(mockResult, nil)
+			mockResult.EXPECT().RowsAffected().Return(int64(2), nil).Times(2)
+			mockTx.EXPECT().Commit().Return(nil)
+		})
+
+		It("reports the rows affected by every statement", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(meta.RowsAffected).NotTo(BeNil())
+			Expect(*meta.RowsAffected).To(Equal(int64(4)))
+		})
+	})
+
+	Describe("BATCH RESULTS", func() {
+		var first, second []map[string]any
+
+		BeforeEach(func() {
+			req = ex.Bulk(ex.System("some-instruction"), ex.Exec("some-stmt"), ex.Exec("other-stmt"), ex.Exec("last-stmt"))
+			data = ex.Results{&first, &second}
+
+			mockConnection.EXPECT().BeginTx(gomock.Any(), gomock.Any()).Return(mockTx, nil)
+			mockTx.EXPECT().Rollback().Return(nil)
+			mockTx.EXPECT().ExecContext(ctx, "some-instruction").Return(mockResult, nil)
+			mockTx.EXPECT().QueryContext(ctx, "some-stmt").Return(mockRows, nil)
+			mockScanner.EXPECT().Scan(mockRows, &first).Return(nil)
+			mockTx.EXPECT().QueryContext(ctx, "other-stmt").Return(mockTypeRows, nil)
+			mockScanner.EXPECT().Scan(mockTypeRows, &second).Return(nil)
+			mockRows.EXPECT().Close().Return(nil)
+			mockTypeRows.EXPECT().Close().Return(nil)
+			mockTx.EXPECT().ExecContext(ctx, "last-stmt").Return(mockResult, nil)
+			mockTx.EXPECT().Commit().Return(nil)
+		})
+
+		It("scans each result into its destination", func() {
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Describe("PARTIAL BATCH", func() {
+		var results []ex.BatchResult
+
+		BeforeEach(func() {
+			req = ex.Partial(ex.System("some-instruction"), ex.Exec("some-stmt"), ex.Exec("other-stmt"))
+			data = &results
+
+			mockConnection.EXPECT().BeginTx(gomock.Any(), gomock.Any()).Return(mockTx, nil)
+			mockTx.EXPECT().Rollback().Return(nil)
+			mockTx.EXPECT().ExecContext(ctx, "some-instruction").Return(mockResult, nil)
+			mockTx.EXPECT().ExecContext(ctx, "SAVEPOINT ex_0").Return(mockResult, nil)
+			mockTx.EXPECT().ExecContext(gomock.Any(), "some-stmt").Return(mockResult, nil)
+			mockResult.EXPECT().RowsAffected().Return(int64(2), nil)
+			mockTx.EXPECT().ExecContext(ctx, "RELEASE SAVEPOINT ex_0").Return(mockResult, nil)
+			mockTx.EXPECT().ExecContext(ctx, "SAVEPOINT ex_1").Return(mockResult, nil)
+			mockTx.EXPECT().ExecContext(gomock.Any(), "other-stmt").Return(nil, errors.New("nope"))
+			mockTx.EXPECT().ExecContext(ctx, "ROLLBACK TO SAVEPOINT ex_1").Return(mockResult, nil)
+			mockTx.EXPECT().Commit().Return(nil)
+		})
+
+		It("rolls back the failed requests and commits the rest", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(results).To(Equal([]ex.BatchResult{
+				{Index: 0, RowsAffected: 2},
+				{Index: 1, Error: "nope"},
+			}))
+		})
+	})
+
 	Describe("DELETE", func() {
 		BeforeEach(func() {
 			req = ex.Delete("resources")
@@ -215,7 +1020,7 @@ var _ = Describe("Executor", func() {
 
 		Context("when beginning a tx fails", func() {
 			BeforeEach(func() {
-				mockConnection.EXPECT().Begin().Return(nil, errors.New("nope"))
+				mockConnection.EXPECT().BeginTx(gomock.Any(), gomock.Any()).Return(nil, errors.New("nope"))
 			})
 
 			It("errors", func() {
@@ -226,7 +1031,7 @@ var _ = Describe("Executor", func() {
 		Context("when beginning a tx succeeds", func() {
 			BeforeEach(func() {
 				mockTx.EXPECT().Rollback().Return(nil)
-				mockConnection.EXPECT().Begin().Return(mockTx, nil)
+				mockConnection.EXPECT().BeginTx(gomock.Any(), gomock.Any()).Return(mockTx, nil)
 			})
 
 			Context("when querying column types succeeeds", func() {
@@ -417,7 +1222,7 @@ var _ = Describe("Executor", func() {
 
 		Context("when beginning a tx fails", func() {
 			BeforeEach(func() {
-				mockConnection.EXPECT().Begin().Return(nil, errors.New("nope"))
+				mockConnection.EXPECT().BeginTx(gomock.Any(), gomock.Any()).Return(nil, errors.New("nope"))
 			})
 
 			It("errors", func() {
@@ -428,7 +1233,7 @@ var _ = Describe("Executor", func() {
 		Context("when beginning a tx succeeds", func() {
 			BeforeEach(func() {
 				mockTx.EXPECT().Rollback().Return(nil)
-				mockConnection.EXPECT().Begin().Return(mockTx, nil)
+				mockConnection.EXPECT().BeginTx(gomock.Any(), gomock.Any()).Return(mockTx, nil)
 			})
 
 			Context("when querying column types succeeeds", func() {
@@ -621,7 +1426,7 @@ var _ = Describe("Executor", func() {
 
 		Context("when beginning a tx fails", func() {
 			BeforeEach(func() {
-				mockConnection.EXPECT().Begin().Return(nil, errors.New("nope"))
+				mockConnection.EXPECT().BeginTx(gomock.Any(), gomock.Any()).Return(nil, errors.New("nope"))
 			})
 
 			It("errors", func() {
@@ -632,7 +1437,7 @@ var _ = Describe("Executor", func() {
 		Context("when beginning a tx succeeds", func() {
 			BeforeEach(func() {
 				mockTx.EXPECT().Rollback().Return(nil)
-				mockConnection.EXPECT().Begin().Return(mockTx, nil)
+				mockConnection.EXPECT().BeginTx(gomock.Any(), gomock.Any()).Return(mockTx, nil)
 			})
 
 			Context("when querying column types succeeeds", func() {
@@ -801,6 +1606,71 @@ var _ = Describe("Executor", func() {
 	})
 })
 
+var _ = Describe("Column types", func() {
+
+	var (
+		err error
+
+		mockCtrl       *gomock.Controller
+		mockConnection *mocks.MockConnection
+		mockFormatter  *mocks.MockFormatter
+		mockScanner    *mocks.MockScanner
+		mockTx         *mocks.MockTx
+		mockTypeRows   *mocks.MockRows
+		mockKeyRows    *mocks.MockRows
+		mockRows       *mocks.MockRows
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockConnection = mocks.NewMockConnection(mockCtrl)
+		mockFormatter = mocks.NewMockFormatter(mockCtrl)
+		mockScanner = mocks.NewMockScanner(mockCtrl)
+		mockTx = mocks.NewMockTx(mockCtrl)
+		mockTypeRows = mocks.NewMockRows(mockCtrl)
+		mockKeyRows = mocks.NewMockRows(mockCtrl)
+		mockRows = mocks.NewMockRows(mockCtrl)
+
+		ctx := context.Background()
+
+		mockConnection.EXPECT().BeginTx(gomock.Any(), gomock.Any()).Return(mockTx, nil)
+		mockTx.EXPECT().Rollback().Return(nil)
+
+		// A connection runs one query at a time, so the column types are
+		// closed before the primary key is queried
+		gomock.InOrder(
+			mockTx.EXPECT().QueryContext(ctx, "SELECT * FROM resources LIMIT 0").Return(mockTypeRows, nil),
+			mockTypeRows.EXPECT().ColumnTypes().Return([]xsql.ColumnType{
+				columnType{name: "id", scanType: reflect.TypeOf(int64(0)), databaseTypeName: "INTEGER"},
+			}, nil),
+			mockTypeRows.EXPECT().Close().Return(nil),
+			mockTx.EXPECT().QueryContext(ctx, "some-key-stmt", "resources").Return(mockKeyRows, nil),
+			mockKeyRows.EXPECT().Next().Return(false),
+			mockKeyRows.EXPECT().Err().Return(nil),
+			mockKeyRows.EXPECT().Close().Return(nil),
+			mockFormatter.EXPECT().Format(ex.Query("resources"), gomock.Any()).Return(ex.Statement{Stmt: "some-query"}, nil),
+			mockTx.EXPECT().QueryContext(ctx, "some-query").Return(mockRows, nil),
+			mockScanner.EXPECT().Scan(mockRows, nil).Return(nil),
+			mockRows.EXPECT().Close().Return(nil),
+			mockTx.EXPECT().Commit().Return(nil),
+		)
+
+		executor := xsql.NewExecutor(newLogger(),
+			xsql.WithConnection(mockConnection),
+			xsql.WithFormatter(primaryKeyFormatter{mockFormatter}),
+			xsql.WithScanner(mockScanner),
+			xsql.WithTracer(noopTracer{}),
+			xsql.WithTypeCacheDuration(0),
+		)
+
+		_, err = executor.Execute(ctx, ex.Query("resources"), nil)
+	})
+
+	It("closes the column types before querying the primary key", func() {
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
+
 type noopSpan struct{}
 
 func (s noopSpan) Finish() {}
@@ -832,6 +1702,30 @@ func (l *logger) Infof(format string, args ...any) {
 	fmt.Fprintf(GinkgoWriter, format, args...)
 }
 
+type returningFormatter struct {
+	*mocks.MockFormatter
+}
+
+func (f returningFormatter) SupportsReturning() bool {
+	return true
+}
+
+type primaryKeyFormatter struct {
+	*mocks.MockFormatter
+}
+
+func (f primaryKeyFormatter) FormatPrimaryKey(resource string) ex.Statement {
+	return ex.Exec("some-key-stmt", resource)
+}
+
+type bulkFormatter struct {
+	*mocks.MockFormatter
+}
+
+func (f bulkFormatter) MaxPlaceholders() int {
+	return 2
+}
+
 type columnType struct {
 	name             string
 	scanType         reflect.Type
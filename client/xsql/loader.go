@@ -0,0 +1,165 @@
+package xsql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"github.com/reverted/ex"
+)
+
+func NewCopyLoader() *copyLoader {
+	return &copyLoader{}
+}
+
+// copyLoader streams rows into postgres using COPY FROM STDIN.
+type copyLoader struct{}
+
+func (l *copyLoader) Load(ctx context.Context, tx Tx, load ex.Load) error {
+
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn(load.Resource, load.Columns...))
+	if err != nil {
+		return err
+	}
+
+	defer stmt.Close()
+
+	for {
+		row, err := load.Rows.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		args := make([]any, len(row))
+		for i, value := range row {
+			args[i] = loadValue(value)
+		}
+
+		if _, err := stmt.ExecContext(ctx, args...); err != nil {
+			return err
+		}
+	}
+
+	// An exec without args flushes the buffered rows
+	_, err = stmt.ExecContext(ctx)
+	return err
+}
+
+func NewLocalInfileLoader() *localInfileLoader {
+	return &localInfileLoader{}
+}
+
+// localInfileLoader streams rows into mysql using LOAD DATA LOCAL INFILE,
+// which requires local_infile to be enabled on the server.
+type localInfileLoader struct{}
+
+// infileCount numbers the readers of every loader, since the driver
+// registers them by name for the whole process.
+var infileCount atomic.Int64
+
+func (l *localInfileLoader) Load(ctx context.Context, tx Tx, load ex.Load) error {
+
+	name := fmt.Sprintf("ex-load-%d", infileCount.Add(1))
+
+	reader, writer := io.Pipe()
+	defer reader.Close()
+
+	go func() {
+		writer.CloseWithError(writeInfile(writer, load.Rows))
+	}()
+
+	mysql.RegisterReaderHandler(name, func() io.Reader { return reader })
+	defer mysql.DeregisterReaderHandler(name)
+
+	stmt := fmt.Sprintf(
+		"LOAD DATA LOCAL INFILE 'Reader::%s' INTO TABLE %s (%s)",
+		name,
+		load.Resource,
+		strings.Join(load.Columns, ","),
+	)
+
+	_, err := tx.ExecContext(ctx, stmt)
+	return err
+}
+
+// writeInfile writes rows using the default LOAD DATA format: tab separated
+// fields, newline terminated lines and backslash escapes.
+func writeInfile(w io.Writer, rows ex.RowIterator) error {
+
+	for {
+		row, err := rows.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		fields := make([]string, len(row))
+		for i, value := range row {
+			fields[i] = infileValue(loadValue(value))
+		}
+
+		if _, err := io.WriteString(w, strings.Join(fields, "\t")+"\n"); err != nil {
+			return err
+		}
+	}
+}
+
+var infileEscaper = strings.NewReplacer(
+	"\\", "\\\\",
+	"\t", "\\t",
+	"\n", "\\n",
+	"\r", "\\r",
+	"\x00", "\\0",
+)
+
+func infileValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "\\N"
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	case []byte:
+		return infileEscaper.Replace(string(v))
+	default:
+		return infileEscaper.Replace(fmt.Sprint(v))
+	}
+}
+
+func loadValue(value any) any {
+	switch v := value.(type) {
+	case ex.JsonArg:
+		data, _ := json.Marshal(v.Arg)
+		return string(data)
+
+	case time.Time:
+		return v.Format(ex.SqlTimeFormat)
+
+	case nil, []byte:
+		return v
+
+	default:
+		switch reflect.ValueOf(v).Kind() {
+		case reflect.Slice, reflect.Map:
+			data, _ := json.Marshal(v)
+			return string(data)
+
+		default:
+			return v
+		}
+	}
+}
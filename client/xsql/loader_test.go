@@ -0,0 +1,158 @@
+package xsql_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/golang/mock/gomock"
+	"github.com/reverted/ex"
+	"github.com/reverted/ex/client/xsql"
+	"github.com/reverted/ex/client/xsql/mocks"
+)
+
+type Loader interface {
+	Load(context.Context, xsql.Tx, ex.Load) error
+}
+
+var _ = Describe("Loader", func() {
+
+	var (
+		err error
+
+		load ex.Load
+
+		mockCtrl   *gomock.Controller
+		mockTx     *mocks.MockTx
+		mockStmt   *mocks.MockStmt
+		mockResult *mocks.MockResult
+
+		ctx    context.Context
+		loader Loader
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockTx = mocks.NewMockTx(mockCtrl)
+		mockStmt = mocks.NewMockStmt(mockCtrl)
+		mockResult = mocks.NewMockResult(mockCtrl)
+
+		ctx = context.Background()
+
+		load = ex.BulkLoad("resources", []string{"id", "data"}, ex.IterateRows(
+			[]any{1, map[string]any{"key": "value"}},
+			[]any{2, nil},
+		))
+	})
+
+	JustBeforeEach(func() {
+		err = loader.Load(ctx, mockTx, load)
+	})
+
+	Describe("copy", func() {
+		BeforeEach(func() {
+			loader = xsql.NewCopyLoader()
+		})
+
+		Context("when preparing the copy fails", func() {
+			BeforeEach(func() {
+				mockTx.EXPECT().PrepareContext(ctx, `COPY "resources" ("id", "data") FROM STDIN`).Return(nil, errors.New("nope"))
+			})
+
+			It("errors", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		Context("when preparing the copy succeeds", func() {
+			BeforeEach(func() {
+				mockTx.EXPECT().PrepareContext(ctx, `COPY "resources" ("id", "data") FROM STDIN`).Return(mockStmt, nil)
+				mockStmt.EXPECT().Close().Return(nil)
+			})
+
+			Context("when copying a row fails", func() {
+				BeforeEach(func() {
+					mockStmt.EXPECT().ExecContext(ctx, 1, `{"key":"value"}`).Return(nil, errors.New("nope"))
+				})
+
+				It("errors", func() {
+					Expect(err).To(HaveOccurred())
+				})
+			})
+
+			Context("when copying the rows succeeds", func() {
+				BeforeEach(func() {
+					gomock.InOrder(
+						mockStmt.EXPECT().ExecContext(ctx, 1, `{"key":"value"}`).Return(mockResult, nil),
+						mockStmt.EXPECT().ExecContext(ctx, 2, nil).Return(mockResult, nil),
+						mockStmt.EXPECT().ExecContext(ctx).Return(mockResult, nil),
+					)
+				})
+
+				It("flushes the copy", func() {
+					Expect(err).NotTo(HaveOccurred())
+				})
+			})
+		})
+	})
+
+	Describe("local infile", func() {
+		BeforeEach(func() {
+			loader = xsql.NewLocalInfileLoader()
+
+			load = ex.BulkLoad("resources", []string{"id", "name", "created_at"}, ex.IterateRows(
+				[]any{1, "tab\there", time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)},
+				[]any{2, nil, true},
+			))
+		})
+
+		Context("when the load succeeds", func() {
+			BeforeEach(func() {
+				mockTx.EXPECT().ExecContext(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, stmt string, _ ...any) (xsql.Result, error) {
+					Expect(stmt).To(MatchRegexp(`^LOAD DATA LOCAL INFILE 'Reader::ex-load-\d+' INTO TABLE resources \(id,name,created_at\)$`))
+					return mockResult, nil
+				})
+			})
+
+			It("succeeds", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("when another loader has loaded", func() {
+			var readers []string
+
+			BeforeEach(func() {
+				readers = nil
+
+				mockTx.EXPECT().ExecContext(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, stmt string, _ ...any) (xsql.Result, error) {
+					readers = append(readers, regexp.MustCompile(`Reader::([^']+)`).FindStringSubmatch(stmt)[1])
+					return mockResult, nil
+				}).Times(2)
+
+				other := ex.BulkLoad("others", []string{"id"}, ex.IterateRows([]any{3}))
+				Expect(xsql.NewLocalInfileLoader().Load(ctx, mockTx, other)).To(Succeed())
+			})
+
+			It("reads the rows from another reader", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(readers).To(HaveLen(2))
+				Expect(readers[0]).NotTo(Equal(readers[1]))
+			})
+		})
+
+		Context("when the load fails", func() {
+			BeforeEach(func() {
+				mockTx.EXPECT().ExecContext(ctx, gomock.Any()).Return(nil, errors.New("nope"))
+			})
+
+			It("errors", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+})
@@ -5,59 +5,48 @@
 package mocks
 
 import (
+	context "context"
+	sql "database/sql"
+	reflect "reflect"
+
 	gomock "github.com/golang/mock/gomock"
 	xsql "github.com/reverted/ex/client/xsql"
-	reflect "reflect"
 )
 
-// MockConnection is a mock of Connection interface
+// MockConnection is a mock of Connection interface.
 type MockConnection struct {
 	ctrl     *gomock.Controller
 	recorder *MockConnectionMockRecorder
 }
 
-// MockConnectionMockRecorder is the mock recorder for MockConnection
+// MockConnectionMockRecorder is the mock recorder for MockConnection.
 type MockConnectionMockRecorder struct {
 	mock *MockConnection
 }
 
-// NewMockConnection creates a new mock instance
+// NewMockConnection creates a new mock instance.
 func NewMockConnection(ctrl *gomock.Controller) *MockConnection {
 	mock := &MockConnection{ctrl: ctrl}
 	mock.recorder = &MockConnectionMockRecorder{mock}
 	return mock
 }
 
-// EXPECT returns an object that allows the caller to indicate expected use
+// EXPECT returns an object that allows the caller to indicate expected use.
 func (m *MockConnection) EXPECT() *MockConnectionMockRecorder {
 	return m.recorder
 }
 
-// Begin mocks base method
-func (m *MockConnection) Begin() (xsql.Tx, error) {
+// BeginTx mocks base method.
+func (m *MockConnection) BeginTx(arg0 context.Context, arg1 *sql.TxOptions) (xsql.Tx, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Begin")
+	ret := m.ctrl.Call(m, "BeginTx", arg0, arg1)
 	ret0, _ := ret[0].(xsql.Tx)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// Begin indicates an expected call of Begin
-func (mr *MockConnectionMockRecorder) Begin() *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Begin", reflect.TypeOf((*MockConnection)(nil).Begin))
-}
-
-// Close mocks base method
-func (m *MockConnection) Close() error {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Close")
-	ret0, _ := ret[0].(error)
-	return ret0
-}
-
-// Close indicates an expected call of Close
-func (mr *MockConnectionMockRecorder) Close() *gomock.Call {
+// BeginTx indicates an expected call of BeginTx.
+func (mr *MockConnectionMockRecorder) BeginTx(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockConnection)(nil).Close))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginTx", reflect.TypeOf((*MockConnection)(nil).BeginTx), arg0, arg1)
 }
@@ -88,6 +88,21 @@ func (mr *MockTxMockRecorder) ExecContext(arg0, arg1 interface{}, arg2 ...interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecContext", reflect.TypeOf((*MockTx)(nil).ExecContext), varargs...)
 }
 
+// PrepareContext mocks base method
+func (m *MockTx) PrepareContext(arg0 context.Context, arg1 string) (xsql.Stmt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PrepareContext", arg0, arg1)
+	ret0, _ := ret[0].(xsql.Stmt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PrepareContext indicates an expected call of PrepareContext
+func (mr *MockTxMockRecorder) PrepareContext(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PrepareContext", reflect.TypeOf((*MockTx)(nil).PrepareContext), arg0, arg1)
+}
+
 // Query mocks base method
 func (m *MockTx) Query(arg0 string, arg1 ...interface{}) (xsql.Rows, error) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,75 @@
+package xsql_test
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/golang/mock/gomock"
+	"github.com/reverted/ex"
+	"github.com/reverted/ex/client/xsql"
+	"github.com/reverted/ex/client/xsql/mocks"
+)
+
+var _ = Describe("Replicas", func() {
+
+	var (
+		mockCtrl    *gomock.Controller
+		mockPrimary *mocks.MockConnection
+		mockReplica *mocks.MockConnection
+		mockOther   *mocks.MockConnection
+
+		ctx      context.Context
+		executor interface {
+			Executor
+			Transactor
+		}
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockPrimary = mocks.NewMockConnection(mockCtrl)
+		mockReplica = mocks.NewMockConnection(mockCtrl)
+		mockOther = mocks.NewMockConnection(mockCtrl)
+
+		ctx = context.Background()
+
+		executor = xsql.NewExecutor(newLogger(),
+			xsql.WithConnection(mockPrimary),
+			xsql.WithReplicas(mockReplica, mockOther),
+			xsql.WithTracer(noopTracer{}),
+		)
+	})
+
+	It("sends queries to the replicas in turn", func() {
+		mockReplica.EXPECT().BeginTx(gomock.Any(), gomock.Any()).Return(nil, errors.New("nope")).Times(2)
+		mockOther.EXPECT().BeginTx(gomock.Any(), gomock.Any()).Return(nil, errors.New("nope"))
+
+		executor.Execute(ctx, ex.Query("resources"), nil)
+		executor.Execute(ctx, ex.Bulk(ex.Query("resources"), ex.Query("others")), nil)
+		executor.Execute(ctx, ex.Bulk(ex.System("SET @user_id = 1"), ex.Query("resources")), nil)
+	})
+
+	It("sends writes to the primary", func() {
+		mockPrimary.EXPECT().BeginTx(gomock.Any(), gomock.Any()).Return(nil, errors.New("nope")).Times(3)
+
+		executor.Execute(ctx, ex.Insert("resources", ex.Values{"name": "some-name"}), nil)
+		executor.Execute(ctx, ex.Exec("some-stmt"), nil)
+		executor.Execute(ctx, ex.Bulk(ex.Query("resources"), ex.Delete("resources")), nil)
+	})
+
+	It("sends queries that read from the primary to the primary", func() {
+		mockPrimary.EXPECT().BeginTx(gomock.Any(), gomock.Any()).Return(nil, errors.New("nope"))
+
+		executor.Execute(ctx, ex.Query("resources", ex.ReadPrimary()), nil)
+	})
+
+	It("begins transactions on the primary", func() {
+		mockPrimary.EXPECT().BeginTx(gomock.Any(), gomock.Any()).Return(nil, errors.New("nope"))
+
+		_, err := executor.Begin(ctx)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -9,6 +9,8 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/reverted/ex"
 )
 
 type Scannable interface {
@@ -23,6 +25,10 @@ type scanner struct{}
 
 func (s *scanner) Scan(rows Rows, data any) error {
 
+	if fn, ok := data.(ex.StreamFunc); ok {
+		return s.streamRows(rows, fn)
+	}
+
 	t := reflect.TypeOf(data)
 	v := reflect.ValueOf(data)
 
@@ -75,6 +81,22 @@ func (s *scanner) queryRows(rows Rows, t reflect.Type, v reflect.Value) error {
 	return nil
 }
 
+func (s *scanner) streamRows(rows Rows, fn ex.StreamFunc) error {
+
+	for rows.Next() {
+		item := map[string]any{}
+		if err := s.scanMap(rows, item); err != nil {
+			return err
+		}
+
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
 func (s *scanner) queryRow(rows Rows, t reflect.Type, v reflect.Value) error {
 
 	if rows.Next() {
@@ -99,7 +121,7 @@ func (s *scanner) queryRow(rows Rows, t reflect.Type, v reflect.Value) error {
 	}
 
 	if v.Kind() == reflect.Ptr && v.IsNil() {
-		return errors.New("not found")
+		return ex.NewError(ex.NotFound, errors.New("not found"))
 	}
 
 	return nil
@@ -9,6 +9,7 @@ import (
 	. "github.com/onsi/gomega"
 
 	"github.com/golang/mock/gomock"
+	"github.com/reverted/ex"
 	"github.com/reverted/ex/client/xsql"
 	"github.com/reverted/ex/client/xsql/mocks"
 )
@@ -154,6 +155,81 @@ var _ = Describe("Scanner", func() {
 		})
 	})
 
+	Describe("streaming rows", func() {
+		var res []map[string]any
+		var streamErr error
+
+		BeforeEach(func() {
+			res = nil
+			streamErr = nil
+
+			mockRows.EXPECT().ColumnTypes().Return([]xsql.ColumnType{
+				column{"key1", reflect.TypeOf(sql.NullString{}), "TEXT"},
+			}, nil)
+			mockRows.EXPECT().Scan(gomock.Any()).Return(nil)
+		})
+
+		JustBeforeEach(func() {
+			err = scanner.Scan(mockRows, ex.StreamFunc(func(row map[string]any) error {
+				res = append(res, row)
+				return streamErr
+			}))
+		})
+
+		Context("when the stream fails", func() {
+			BeforeEach(func() {
+				streamErr = errors.New("nope")
+			})
+
+			It("errors", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		Context("when the stream succeeds", func() {
+			BeforeEach(func() {
+				mockRows.EXPECT().Next().Return(false).Times(1)
+				mockRows.EXPECT().Err().Return(nil)
+			})
+
+			It("passes each row to the stream", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(res).To(HaveLen(1))
+				Expect(res[0]).To(HaveKey("key1"))
+			})
+		})
+	})
+
+	Describe("scanning aggregates into a map", func() {
+		var res []map[string]any
+
+		BeforeEach(func() {
+			res = []map[string]any{}
+
+			mockRows.EXPECT().ColumnTypes().Return([]xsql.ColumnType{
+				column{"COUNT(id)", reflect.TypeOf(sql.NullInt64{}), "BIGINT"},
+				column{"total", reflect.TypeOf(sql.RawBytes{}), "DECIMAL"},
+				column{"average", reflect.TypeOf(sql.RawBytes{}), "NUMERIC"},
+				column{"count", reflect.TypeOf(sql.NullInt64{}), "INT8"},
+			}, nil)
+			mockRows.EXPECT().Scan(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+			mockRows.EXPECT().Next().Return(false).Times(1)
+			mockRows.EXPECT().Err().Return(nil)
+		})
+
+		JustBeforeEach(func() {
+			err = scanner.Scan(mockRows, &res)
+		})
+
+		It("scans the correct numeric types", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res[0]["COUNT(id)"]).To(BeAssignableToTypeOf(0))
+			Expect(res[0]["total"]).To(BeAssignableToTypeOf(0.0))
+			Expect(res[0]["average"]).To(BeAssignableToTypeOf(0.0))
+			Expect(res[0]["count"]).To(BeAssignableToTypeOf(0))
+		})
+	})
+
 	Describe("scanning with tags", func() {
 		var res []*result
 
@@ -356,6 +432,35 @@ var _ = Describe("Scanner", func() {
 	})
 })
 
+var _ = Describe("Scanner without rows", func() {
+
+	var (
+		err error
+
+		mockCtrl *gomock.Controller
+		mockRows *mocks.MockRows
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockRows = mocks.NewMockRows(mockCtrl)
+		mockRows.EXPECT().Next().Return(false)
+		mockRows.EXPECT().Err().Return(nil)
+	})
+
+	Context("when scanning a single row", func() {
+		var res *result
+
+		BeforeEach(func() {
+			err = xsql.NewScanner().Scan(mockRows, &res)
+		})
+
+		It("errors with not found", func() {
+			Expect(ex.CodeOf(err)).To(Equal(ex.NotFound))
+		})
+	})
+})
+
 type column struct {
 	name     string
 	scanType reflect.Type
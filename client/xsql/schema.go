@@ -0,0 +1,184 @@
+package xsql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/reverted/ex"
+)
+
+// Resources returns the names of the resources the validator permits.
+func (e *executor) Resources(ctx context.Context) ([]string, error) {
+
+	f, ok := e.Formatter.(SchemaFormatter)
+	if !ok {
+		return nil, errors.New("formatter does not support describe")
+	}
+
+	tx, err := e.begin(ctx, e.connection(ex.Query("")))
+	if err != nil {
+		return nil, e.formatError(err)
+	}
+
+	defer tx.Rollback()
+
+	resources := []string{}
+
+	err = e.queryRows(ctx, tx, f.FormatResourceNames(), func(rows Rows) error {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+
+		if e.Validator.Validate(ex.Query(name), nil) == nil {
+			resources = append(resources, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, e.formatError(err)
+	}
+
+	return resources, nil
+}
+
+// Describe returns the columns, keys and indexes of a resource from the
+// database's catalog.
+func (e *executor) Describe(ctx context.Context, resource string) (ex.Schema, error) {
+
+	f, ok := e.Formatter.(SchemaFormatter)
+	if !ok {
+		return ex.Schema{}, errors.New("formatter does not support describe")
+	}
+
+	// Resources the validator rejects are reported as missing, the same as
+	// they're left out of Resources
+	if err := e.Validator.Validate(ex.Query(resource), nil); err != nil {
+		return ex.Schema{}, ex.NewError(ex.NotFound, fmt.Errorf("unknown resource: %s", resource))
+	}
+
+	tx, err := e.begin(ctx, e.connection(ex.Query(resource)))
+	if err != nil {
+		return ex.Schema{}, e.formatError(err)
+	}
+
+	defer tx.Rollback()
+
+	schema, err := e.describe(ctx, tx, f, resource)
+	if err != nil {
+		return ex.Schema{}, e.formatError(err)
+	}
+
+	return schema, nil
+}
+
+func (e *executor) describe(ctx context.Context, tx Tx, f SchemaFormatter, resource string) (ex.Schema, error) {
+
+	schema := ex.Schema{
+		Resource:    resource,
+		Columns:     []ex.Column{},
+		Indexes:     []ex.Index{},
+		ForeignKeys: []ex.ForeignKey{},
+	}
+
+	err := e.queryRows(ctx, tx, f.FormatColumnSchema(resource), func(rows Rows) error {
+		var column ex.Column
+		if err := rows.Scan(&column.Name, &column.Type, &column.Nullable); err != nil {
+			return err
+		}
+		schema.Columns = append(schema.Columns, column)
+		return nil
+	})
+	if err != nil {
+		return schema, err
+	}
+
+	if len(schema.Columns) == 0 {
+		return schema, ex.NewError(ex.NotFound, fmt.Errorf("unknown resource: %s", resource))
+	}
+
+	if v, ok := e.Validator.(PatternValidator); ok {
+		schema.ColumnPatterns = v.PermittedColumnPatterns()
+	}
+
+	if schema.PrimaryKey, err = e.describePrimaryKey(ctx, tx, resource); err != nil {
+		return schema, err
+	}
+
+	err = e.queryRows(ctx, tx, f.FormatIndexSchema(resource), func(rows Rows) error {
+		var name, column string
+		var unique bool
+		if err := rows.Scan(&name, &column, &unique); err != nil {
+			return err
+		}
+
+		// Rows are ordered by index, one for each of its columns
+		if n := len(schema.Indexes); n > 0 && schema.Indexes[n-1].Name == name {
+			schema.Indexes[n-1].Columns = append(schema.Indexes[n-1].Columns, column)
+		} else {
+			schema.Indexes = append(schema.Indexes, ex.Index{Name: name, Columns: []string{column}, Unique: unique})
+		}
+		return nil
+	})
+	if err != nil {
+		return schema, err
+	}
+
+	err = e.queryRows(ctx, tx, f.FormatForeignKeySchema(resource), func(rows Rows) error {
+		var name, column, referenced, referencedColumn string
+		if err := rows.Scan(&name, &column, &referenced, &referencedColumn); err != nil {
+			return err
+		}
+
+		if n := len(schema.ForeignKeys); n > 0 && schema.ForeignKeys[n-1].Name == name {
+			key := &schema.ForeignKeys[n-1]
+			key.Columns = append(key.Columns, column)
+			key.ReferencedColumns = append(key.ReferencedColumns, referencedColumn)
+		} else {
+			schema.ForeignKeys = append(schema.ForeignKeys, ex.ForeignKey{
+				Name:              name,
+				Columns:           []string{column},
+				Resource:          referenced,
+				ReferencedColumns: []string{referencedColumn},
+			})
+		}
+		return nil
+	})
+
+	return schema, err
+}
+
+// describePrimaryKey prefers the primary key configured with WithPrimaryKey.
+func (e *executor) describePrimaryKey(ctx context.Context, tx Tx, resource string) ([]string, error) {
+	e.Lock()
+	key, ok := e.PrimaryKeys[resource]
+	e.Unlock()
+
+	if ok {
+		return key, nil
+	}
+
+	key, err := e.queryPrimaryKey(ctx, tx, resource)
+	if key == nil {
+		key = []string{}
+	}
+	return key, err
+}
+
+func (e *executor) queryRows(ctx context.Context, tx Tx, stmt ex.Statement, scan func(Rows) error) error {
+
+	rows, err := e.queryContext(ctx, tx, stmt)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,206 @@
+package xsql_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/golang/mock/gomock"
+	"github.com/reverted/ex"
+	"github.com/reverted/ex/client/xsql"
+	"github.com/reverted/ex/client/xsql/mocks"
+)
+
+type Describer interface {
+	Describe(context.Context, string) (ex.Schema, error)
+}
+
+var _ = Describe("Describe", func() {
+
+	var (
+		err    error
+		schema ex.Schema
+
+		mockCtrl       *gomock.Controller
+		mockConnection *mocks.MockConnection
+		mockTx         *mocks.MockTx
+
+		tables    map[string][][]any
+		describer Describer
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockConnection = mocks.NewMockConnection(mockCtrl)
+		mockTx = mocks.NewMockTx(mockCtrl)
+
+		tables = map[string][][]any{
+			"information_schema.COLUMNS": {
+				{"id", "int", false},
+				{"owner_id", "int", false},
+				{"name", "varchar(160)", true},
+			},
+			"CONSTRAINT_NAME = 'PRIMARY'": {
+				{"id"},
+			},
+			"information_schema.STATISTICS": {
+				{"name_idx", "owner_id", true},
+				{"name_idx", "name", true},
+				{"owner_idx", "owner_id", false},
+			},
+			"REFERENCED_TABLE_NAME IS NOT NULL": {
+				{"owner_fk", "owner_id", "owners", "id"},
+			},
+		}
+
+		mockConnection.EXPECT().BeginTx(gomock.Any(), gomock.Any()).Return(mockTx, nil)
+		mockTx.EXPECT().Rollback().Return(nil)
+		mockTx.EXPECT().QueryContext(gomock.Any(), gomock.Any(), "resources").DoAndReturn(func(ctx context.Context, stmt string, args ...any) (xsql.Rows, error) {
+			for table, rows := range tables {
+				if strings.Contains(stmt, table) {
+					return &fakeRows{rows: rows}, nil
+				}
+			}
+			return &fakeRows{}, nil
+		}).AnyTimes()
+
+		describer = xsql.NewExecutor(newLogger(),
+			xsql.WithConnection(mockConnection),
+			xsql.WithTracer(noopTracer{}),
+		)
+	})
+
+	JustBeforeEach(func() {
+		schema, err = describer.Describe(context.Background(), "resources")
+	})
+
+	It("describes the resource", func() {
+		Expect(err).NotTo(HaveOccurred())
+		Expect(schema).To(Equal(ex.Schema{
+			Resource: "resources",
+			Columns: []ex.Column{
+				{Name: "id", Type: "int"},
+				{Name: "owner_id", Type: "int"},
+				{Name: "name", Type: "varchar(160)", Nullable: true},
+			},
+			PrimaryKey: []string{"id"},
+			Indexes: []ex.Index{
+				{Name: "name_idx", Columns: []string{"owner_id", "name"}, Unique: true},
+				{Name: "owner_idx", Columns: []string{"owner_id"}},
+			},
+			ForeignKeys: []ex.ForeignKey{
+				{Name: "owner_fk", Columns: []string{"owner_id"}, Resource: "owners", ReferencedColumns: []string{"id"}},
+			},
+		}))
+	})
+
+	Context("when the resource has no columns", func() {
+		BeforeEach(func() {
+			delete(tables, "information_schema.COLUMNS")
+		})
+
+		It("errors with not found", func() {
+			Expect(ex.CodeOf(err)).To(Equal(ex.NotFound))
+		})
+	})
+})
+
+var _ = Describe("Describe an invalid resource", func() {
+
+	var (
+		err error
+
+		mockCtrl       *gomock.Controller
+		mockConnection *mocks.MockConnection
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockConnection = mocks.NewMockConnection(mockCtrl)
+
+		describer := xsql.NewExecutor(newLogger(),
+			xsql.WithConnection(mockConnection),
+			xsql.WithTracer(noopTracer{}),
+		)
+
+		_, err = describer.Describe(context.Background(), "resources' OR '1'='1")
+	})
+
+	It("errors with not found without querying the catalog", func() {
+		Expect(ex.CodeOf(err)).To(Equal(ex.NotFound))
+	})
+})
+
+var _ = Describe("Resources", func() {
+
+	var (
+		err       error
+		resources []string
+
+		mockCtrl       *gomock.Controller
+		mockConnection *mocks.MockConnection
+		mockTx         *mocks.MockTx
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockConnection = mocks.NewMockConnection(mockCtrl)
+		mockTx = mocks.NewMockTx(mockCtrl)
+
+		mockConnection.EXPECT().BeginTx(gomock.Any(), gomock.Any()).Return(mockTx, nil)
+		mockTx.EXPECT().Rollback().Return(nil)
+		mockTx.EXPECT().QueryContext(gomock.Any(), gomock.Any()).Return(&fakeRows{rows: [][]any{
+			{"owners"}, {"resources"}, {"secrets"},
+		}}, nil)
+
+		executor := xsql.NewExecutor(newLogger(),
+			xsql.WithConnection(mockConnection),
+			xsql.WithTracer(noopTracer{}),
+			xsql.WithValidator(xsql.NewValidator(newLogger(),
+				xsql.WithPermittedResourcePattern(`^(owners|resources)$`),
+			)),
+		)
+
+		resources, err = executor.Resources(context.Background())
+	})
+
+	It("lists the resources the validator permits", func() {
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resources).To(Equal([]string{"owners", "resources"}))
+	})
+})
+
+type fakeRows struct {
+	rows [][]any
+	row  []any
+}
+
+func (r *fakeRows) Err() error {
+	return nil
+}
+
+func (r *fakeRows) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	r.row, r.rows = r.rows[0], r.rows[1:]
+	return true
+}
+
+func (r *fakeRows) ColumnTypes() ([]xsql.ColumnType, error) {
+	return nil, nil
+}
+
+func (r *fakeRows) Scan(dest ...any) error {
+	for i, value := range r.row {
+		reflect.ValueOf(dest[i]).Elem().Set(reflect.ValueOf(value))
+	}
+	return nil
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
@@ -0,0 +1,147 @@
+package xsql_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
+	"github.com/reverted/ex"
+	"github.com/reverted/ex/client/xsql"
+	"github.com/reverted/ex/client/xsql/mocks"
+)
+
+type Transactor interface {
+	Begin(context.Context) (ex.TxExecutor, error)
+}
+
+var _ = Describe("TxExecutor", func() {
+
+	var (
+		err error
+
+		mockCtrl       *gomock.Controller
+		mockConnection *mocks.MockConnection
+		mockTx         *mocks.MockTx
+		mockResult     *mocks.MockResult
+
+		ctx        context.Context
+		transactor Transactor
+		txExecutor ex.TxExecutor
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockConnection = mocks.NewMockConnection(mockCtrl)
+		mockTx = mocks.NewMockTx(mockCtrl)
+		mockResult = mocks.NewMockResult(mockCtrl)
+
+		ctx = context.Background()
+
+		transactor = xsql.NewExecutor(newLogger(),
+			xsql.WithConnection(mockConnection),
+			xsql.WithTracer(noopTracer{}),
+		)
+	})
+
+	JustBeforeEach(func() {
+		txExecutor, err = transactor.Begin(ctx)
+	})
+
+	Context("when beginning a tx fails", func() {
+		BeforeEach(func() {
+			mockConnection.EXPECT().BeginTx(gomock.Any(), gomock.Any()).Return(nil, errors.New("nope"))
+		})
+
+		It("errors", func() {
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("when beginning a tx succeeds", func() {
+		BeforeEach(func() {
+			mockConnection.EXPECT().BeginTx(gomock.Any(), gomock.Any()).Return(mockTx, nil)
+		})
+
+		It("executes every request in the same tx", func() {
+			Expect(err).NotTo(HaveOccurred())
+
+			mockTx.EXPECT().ExecContext(gomock.Any(), "some-stmt").Return(mockResult, nil).Times(2)
+			mockTx.EXPECT().Commit().Return(nil)
+
+			retry, err := txExecutor.Execute(ctx, ex.Exec("some-stmt"), nil)
+			Expect(retry).To(BeFalse())
+			Expect(err).NotTo(HaveOccurred())
+
+			retry, err = txExecutor.Execute(ctx, ex.Exec("some-stmt"), nil)
+			Expect(retry).To(BeFalse())
+			Expect(err).NotTo(HaveOccurred())
+
+			retry, err = txExecutor.Commit()
+			Expect(retry).To(BeFalse())
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("reports postgres serialization failures as retryable", func() {
+			mockTx.EXPECT().ExecContext(gomock.Any(), "some-stmt").Return(nil, &pq.Error{Code: "40001"})
+
+			retry, err := txExecutor.Execute(ctx, ex.Exec("some-stmt"), nil)
+			Expect(retry).To(BeTrue())
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("reports deadlocks as retryable", func() {
+			mockTx.EXPECT().ExecContext(gomock.Any(), "some-stmt").Return(nil, &mysql.MySQLError{Number: 1213})
+			mockTx.EXPECT().Rollback().Return(nil)
+
+			retry, err := txExecutor.Execute(ctx, ex.Exec("some-stmt"), nil)
+			Expect(retry).To(BeTrue())
+			Expect(err).To(HaveOccurred())
+
+			Expect(txExecutor.Rollback()).To(Succeed())
+		})
+	})
+
+	Context("when the context has tx options", func() {
+		BeforeEach(func() {
+			ctx = ex.WithTxOptions(ctx, ex.TxOptions{Isolation: ex.Serializable, ReadOnly: true})
+
+			mockConnection.EXPECT().BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable, ReadOnly: true}).Return(mockTx, nil)
+		})
+
+		It("begins the tx with the options", func() {
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("when the executor has tx options", func() {
+		BeforeEach(func() {
+			transactor = xsql.NewExecutor(newLogger(),
+				xsql.WithConnection(mockConnection),
+				xsql.WithTracer(noopTracer{}),
+				xsql.WithTxOptions(ex.TxOptions{Isolation: ex.ReadCommitted}),
+			)
+
+			mockConnection.EXPECT().BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelReadCommitted}).Return(mockTx, nil)
+		})
+
+		It("begins the tx with the options", func() {
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("when the isolation level is unsupported", func() {
+		BeforeEach(func() {
+			ctx = ex.WithTxOptions(ctx, ex.TxOptions{Isolation: "SNAPSHOT"})
+		})
+
+		It("errors", func() {
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -26,6 +26,7 @@ var (
 	aliasRegex     = regexp.MustCompile(`(?i)^(.*)\s+AS\s+(?:\w+)$`)
 	randomRegexp   = regexp.MustCompile(`(?i)^RANDOM\(\)$`)
 	literalRegexp  = regexp.MustCompile(`(?i)^(NULL|TRUE|FALSE|NOW\(\))$`)
+	joinOnRegexp   = regexp.MustCompile(`^\s*(\S+)\s*=\s*(\S+)\s*$`)
 )
 
 type validatorOpt func(*validator)
@@ -104,37 +105,56 @@ func (v *validator) Validate(cmd ex.Command, cols map[string]string) error {
 		return fmt.Errorf("invalid resource: %s", cmd.Resource)
 	}
 
+	for _, join := range cmd.JoinConfig {
+		if err := v.validateJoin(cols, join); err != nil {
+			return err
+		}
+	}
+
 	for _, column := range cmd.ColumnConfig {
-		if !v.isValidColumn(cols, column) {
+		if !v.isValidSelectColumn(cols, column) {
 			return fmt.Errorf("invalid select column: %s", column)
 		}
 	}
 
-	for _, column := range cmd.PartitionConfig {
-		if !v.isValidColumn(cols, column) {
-			return fmt.Errorf("invalid partition column: %s", column)
+	for _, column := range cmd.ReturningConfig {
+		if column != "*" && !v.isValidColumn(cols, column) {
+			return fmt.Errorf("invalid returning column: %s", column)
 		}
 	}
 
-	for column, value := range cmd.Where {
+	for _, column := range cmd.PrimaryKeyConfig {
 		if !v.isValidColumn(cols, column) {
-			return fmt.Errorf("invalid where column: %s", column)
-		}
-		if literal, ok := value.(ex.LiteralArg); ok {
-			if !v.LiteralPattern.MatchString(literal.Arg) {
-				return fmt.Errorf("invalid literal value in where clause: %s", literal.Arg)
-			}
+			return fmt.Errorf("invalid primary key column: %s", column)
 		}
 	}
 
-	for column, value := range cmd.Values {
+	for _, column := range cmd.PartitionConfig {
 		if !v.isValidColumn(cols, column) {
-			return fmt.Errorf("invalid value column: %s", column)
+			return fmt.Errorf("invalid partition column: %s", column)
 		}
-		if literal, ok := value.(ex.LiteralArg); ok {
-			if !v.LiteralPattern.MatchString(literal.Arg) {
-				return fmt.Errorf("invalid literal value: %s", literal.Arg)
-			}
+	}
+
+	if err := v.validateConditions(cols, "where", cmd.Where, v.isValidColumn); err != nil {
+		return err
+	}
+
+	if err := v.validateConditions(cols, "having", ex.Where(cmd.Having), v.isValidHavingColumn); err != nil {
+		return err
+	}
+
+	// A partitioned query ranks the rows before any groups are filtered
+	if len(cmd.Having) > 0 && len(cmd.PartitionConfig) > 0 {
+		return fmt.Errorf("invalid having: not supported with partition")
+	}
+
+	if err := v.validateValues(cols, cmd.Values); err != nil {
+		return err
+	}
+
+	for _, row := range cmd.RowsConfig {
+		if err := v.validateValues(cols, row); err != nil {
+			return err
 		}
 	}
 
@@ -150,6 +170,10 @@ func (v *validator) Validate(cmd ex.Command, cols map[string]string) error {
 		}
 	}
 
+	if err := v.validateCursor(cmd); err != nil {
+		return err
+	}
+
 	for _, column := range cmd.OnConflictConfig.Constraint {
 		if !v.isValidColumn(cols, column) {
 			return fmt.Errorf("invalid conflict constraint column: %s", column)
@@ -172,6 +196,181 @@ func (v *validator) Validate(cmd ex.Command, cols map[string]string) error {
 	return nil
 }
 
+func (v *validator) PermittedColumnPatterns() []string {
+
+	var patterns []string
+	for _, pattern := range v.ColumnPatterns {
+		patterns = append(patterns, pattern.String())
+	}
+
+	return patterns
+}
+
+func (v *validator) validateValues(cols map[string]string, values ex.Values) error {
+
+	for column, value := range values {
+		if !v.isValidColumn(cols, column) {
+			return fmt.Errorf("invalid value column: %s", column)
+		}
+		if literal, ok := value.(ex.LiteralArg); ok {
+			if !v.LiteralPattern.MatchString(literal.Arg) {
+				return fmt.Errorf("invalid literal value: %s", literal.Arg)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (v *validator) validateCursor(cmd ex.Command) error {
+
+	cursor := cmd.CursorConfig
+	if cursor.After == "" && cursor.Before == "" {
+		return nil
+	}
+
+	if cursor.After != "" && cursor.Before != "" {
+		return fmt.Errorf("invalid cursor: after and before are mutually exclusive")
+	}
+
+	if len(cmd.OrderConfig) == 0 {
+		return fmt.Errorf("invalid cursor: order is required")
+	}
+
+	if len(cmd.PartitionConfig) > 0 {
+		return fmt.Errorf("invalid cursor: not supported with partition")
+	}
+
+	token := cursor.After
+	if token == "" {
+		token = cursor.Before
+	}
+
+	values, err := ex.DecodeCursor(token)
+	if err != nil {
+		return err
+	}
+
+	if len(values) != len(cmd.OrderConfig) {
+		return fmt.Errorf("invalid cursor: expected %d values, got %d", len(cmd.OrderConfig), len(values))
+	}
+
+	return nil
+}
+
+func (v *validator) validateJoin(cols map[string]string, join ex.JoinClause) error {
+
+	if !v.ResourcePattern.MatchString(join.Resource) {
+		return fmt.Errorf("invalid join resource: %s", join.Resource)
+	}
+
+	switch strings.ToUpper(join.Type) {
+	case "", "INNER", "LEFT":
+	default:
+		return fmt.Errorf("invalid join type: %s", join.Type)
+	}
+
+	if len(join.On) == 0 {
+		return fmt.Errorf("missing join condition: %s", join.Resource)
+	}
+
+	for _, on := range join.On {
+		matches := joinOnRegexp.FindStringSubmatch(on)
+		if len(matches) == 0 {
+			return fmt.Errorf("invalid join condition: %s", on)
+		}
+		for _, column := range matches[1:] {
+			if !v.isValidColumn(cols, column) {
+				return fmt.Errorf("invalid join column: %s", column)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (v *validator) validateConditions(cols map[string]string, clause string, where ex.Where, isValid func(map[string]string, string) bool) error {
+
+	for column, value := range where {
+		switch group := value.(type) {
+		case ex.AndArg:
+			if err := v.validateGroup(cols, clause, group, isValid); err != nil {
+				return err
+			}
+			continue
+
+		case ex.OrArg:
+			if err := v.validateGroup(cols, clause, group, isValid); err != nil {
+				return err
+			}
+			continue
+
+		case ex.NotArg:
+			if err := v.validateConditions(cols, clause, group.Arg, isValid); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !isValid(cols, column) {
+			return fmt.Errorf("invalid %s column: %s", clause, column)
+		}
+
+		args, ok := value.(ex.AllArg)
+		if !ok {
+			args = ex.AllArg{value}
+		}
+
+		for _, arg := range args {
+			if literal, ok := arg.(ex.LiteralArg); ok {
+				if !v.LiteralPattern.MatchString(literal.Arg) {
+					return fmt.Errorf("invalid literal value in %s clause: %s", clause, literal.Arg)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (v *validator) validateGroup(cols map[string]string, clause string, conditions []ex.Where, isValid func(map[string]string, string) bool) error {
+
+	for _, where := range conditions {
+		if err := v.validateConditions(cols, clause, where, isValid); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (v *validator) isValidSelectColumn(cols map[string]string, column string) bool {
+
+	if aggregate, ok := ex.ParseAggregate(column); ok {
+		return v.isValidAggregate(cols, aggregate)
+	}
+
+	return v.isValidColumn(cols, column)
+}
+
+func (v *validator) isValidHavingColumn(cols map[string]string, column string) bool {
+
+	if aggregate, ok := ex.ParseAggregate(column); ok {
+		return aggregate.Alias == "" && v.isValidAggregate(cols, aggregate)
+	}
+
+	return v.isValidColumn(cols, column)
+}
+
+func (v *validator) isValidAggregate(cols map[string]string, aggregate ex.AggregateArg) bool {
+
+	if aggregate.Column == "*" {
+		return aggregate.Func == "COUNT" && !aggregate.Distinct
+	}
+
+	return v.isValidColumn(cols, aggregate.Column)
+}
+
 func (v *validator) isValidColumn(cols map[string]string, column string) bool {
 	return v.isValidColumnWithVisited(cols, column, make(map[string]bool))
 }
@@ -75,6 +75,60 @@ var _ = Describe("Validator", func() {
 			})
 		})
 
+		Context("when the cursor matches the order", func() {
+			BeforeEach(func() {
+				cursor, _ := ex.EncodeCursor("name", 10)
+				req = ex.Query("resources", ex.Order("name", "id"), ex.After(cursor))
+			})
+
+			It("succeeds", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("when the cursor has no order", func() {
+			BeforeEach(func() {
+				cursor, _ := ex.EncodeCursor(10)
+				req = ex.Query("resources", ex.After(cursor))
+			})
+
+			It("errors", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		Context("when the cursor doesn't match the order", func() {
+			BeforeEach(func() {
+				cursor, _ := ex.EncodeCursor(10)
+				req = ex.Query("resources", ex.Order("name", "id"), ex.Before(cursor))
+			})
+
+			It("errors", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		Context("when the cursor is malformed", func() {
+			BeforeEach(func() {
+				req = ex.Query("resources", ex.Order("id"), ex.After("invalid!"))
+			})
+
+			It("errors", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		Context("when the cursor is combined with partition by", func() {
+			BeforeEach(func() {
+				cursor, _ := ex.EncodeCursor(10)
+				req = ex.Query("resources", ex.PartitionBy("name"), ex.Order("id"), ex.After(cursor))
+			})
+
+			It("errors", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
 		Context("when partitioning by column that doesn't exist", func() {
 			BeforeEach(func() {
 				req = ex.Query("resources", ex.PartitionBy("invalid"))
@@ -115,6 +169,46 @@ var _ = Describe("Validator", func() {
 			})
 		})
 
+		Context("when a row has a column that doesn't exist", func() {
+			BeforeEach(func() {
+				req = ex.Insert("resources", ex.Rows(ex.Values{"name": "name1"}, ex.Values{"invalid": "name2"}))
+			})
+
+			It("errors", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		Context("when every row has valid columns", func() {
+			BeforeEach(func() {
+				req = ex.Insert("resources", ex.Rows(ex.Values{"name": "name1"}, ex.Values{"id": 2, "name": "name2"}))
+			})
+
+			It("succeeds", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("when the primary key has a column that doesn't exist", func() {
+			BeforeEach(func() {
+				req = ex.Query("resources", ex.PrimaryKey("id", "invalid"))
+			})
+
+			It("errors", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		Context("when the primary key has valid columns", func() {
+			BeforeEach(func() {
+				req = ex.Query("resources", ex.PrimaryKey("id", "name"))
+			})
+
+			It("succeeds", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
 		Context("when partitioning by json path on valid base column", func() {
 			BeforeEach(func() {
 				req = ex.Query("resources", ex.PartitionBy("id->>'key'"))
@@ -205,6 +299,174 @@ var _ = Describe("Validator", func() {
 			})
 		})
 
+		Context("when querying base columns in condition groups", func() {
+			BeforeEach(func() {
+				req = ex.Query("resources", ex.Or(
+					ex.Where{"id": "some-value"},
+					ex.Not(ex.Where{"name": "some-value"}),
+				))
+			})
+
+			It("succeeds", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("when querying an invalid column in a nested condition group", func() {
+			BeforeEach(func() {
+				req = ex.Query("resources", ex.And(
+					ex.Where{"id": "some-value"},
+					ex.Or(ex.Where{"name": "some-value"}, ex.Not(ex.Where{"invalid_column": "some-value"})),
+				))
+			})
+
+			It("errors", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		Context("when joining on qualified columns", func() {
+			BeforeEach(func() {
+				cols["resources.id"] = "INTEGER"
+				cols["owners.id"] = "INTEGER"
+				cols["owners.resource_id"] = "INTEGER"
+
+				req = ex.Query("resources",
+					ex.Columns("resources.id", "owners.id"),
+					ex.LeftJoin("owners", "owners.resource_id = resources.id"),
+					ex.Where{"owners.id": 1},
+				)
+			})
+
+			It("succeeds", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("when joining on a column that doesn't exist", func() {
+			BeforeEach(func() {
+				cols["resources.id"] = "INTEGER"
+				cols["owners.id"] = "INTEGER"
+
+				req = ex.Query("resources", ex.Join("owners", "owners.invalid = resources.id"))
+			})
+
+			It("errors", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		Context("when joining a malformed resource", func() {
+			BeforeEach(func() {
+				req = ex.Query("resources", ex.Join("invalid-resource", "id = id"))
+			})
+
+			It("errors", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		Context("when joining with a malformed condition", func() {
+			BeforeEach(func() {
+				req = ex.Query("resources", ex.Join("owners", "id = id OR 1 = 1"))
+			})
+
+			It("errors", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		Context("when selecting aggregates of base columns", func() {
+			BeforeEach(func() {
+				req = ex.Query("resources",
+					ex.Columns("name", "count(*)"),
+					ex.CountDistinct("id").As("ids"),
+					ex.Max("name"),
+					ex.GroupBy("name"),
+				)
+			})
+
+			It("succeeds", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("when selecting an aggregate of a column that doesn't exist", func() {
+			BeforeEach(func() {
+				req = ex.Query("resources", ex.Sum("invalid").As("total"))
+			})
+
+			It("errors", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		Context("when selecting an aggregate of all columns other than count", func() {
+			BeforeEach(func() {
+				req = ex.Query("resources", ex.Sum("*"))
+			})
+
+			It("errors", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		Context("when filtering groups on aggregates and grouped columns", func() {
+			BeforeEach(func() {
+				req = ex.Query("resources",
+					ex.Columns("name"),
+					ex.Count("id"),
+					ex.GroupBy("name"),
+					ex.Having{"name": ex.NotEq("some-value")},
+					ex.Having(ex.Or(ex.Where{"COUNT(id)": ex.Gt(10)}, ex.Where{"MAX(id)": ex.Lt(5)})),
+				)
+			})
+
+			It("succeeds", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("when filtering groups on an aggregate of a column that doesn't exist", func() {
+			BeforeEach(func() {
+				req = ex.Query("resources", ex.GroupBy("name"), ex.Having{"SUM(invalid)": ex.Gt(10)})
+			})
+
+			It("errors", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		Context("when filtering groups of a partitioned query", func() {
+			BeforeEach(func() {
+				req = ex.Query("resources", ex.PartitionBy("name"), ex.GroupBy("name"), ex.Having{"COUNT(id)": ex.Gt(10)})
+			})
+
+			It("errors", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		Context("when filtering groups on an aliased aggregate", func() {
+			BeforeEach(func() {
+				req = ex.Query("resources", ex.GroupBy("name"), ex.Having{"COUNT(id) AS total": ex.Gt(10)})
+			})
+
+			It("errors", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		Context("when returning a column that doesn't exist", func() {
+			BeforeEach(func() {
+				req = ex.Insert("resources", ex.Values{"name": "some-value"}, ex.Returning("id", "invalid"))
+			})
+
+			It("errors", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
 		Context("when querying a json path on base column with json segment", func() {
 			BeforeEach(func() {
 				req = ex.Query("resources", ex.Where{"id->'key'": "some-value"})
@@ -380,6 +642,17 @@ var _ = Describe("Validator", func() {
 		})
 	})
 
+	Context("when using literal with SQL injection attempt in multiple predicates", func() {
+		BeforeEach(func() {
+			validator = xsql.NewValidator(newLogger())
+			req = ex.Query("resources", ex.Where{"name": ex.All(ex.NotEq("value"), ex.Literal("NULL; DROP TABLE users"))})
+		})
+
+		It("errors", func() {
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
 	Context("when using literal with SQL injection attempt in VALUES", func() {
 		BeforeEach(func() {
 			validator = xsql.NewValidator(newLogger())
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/go-sql-driver/mysql"
 	"github.com/reverted/ex"
 )
 
@@ -57,16 +58,41 @@ func (f *formatter) formatQuery(cmd ex.Command, types map[string]string) ex.Stat
 		stmt = "SELECT * FROM " + cmd.Resource
 	}
 
+	if clause := f.FormatJoin(cmd.JoinConfig); clause != "" {
+		stmt += " " + clause
+	}
+
+	var conditions []string
+
 	if clause, whereArgs := f.FormatWhere(cmd.Where); clause != "" {
-		stmt += " WHERE " + clause
+		conditions = append(conditions, clause)
 		args = append(args, whereArgs...)
 	}
 
+	if clause, cursorArgs := f.FormatCursor(cmd.CursorConfig, cmd.OrderConfig); clause != "" {
+		conditions = append(conditions, clause)
+		args = append(args, cursorArgs...)
+	}
+
+	if len(conditions) > 0 {
+		stmt += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
 	if clause := f.FormatGroupBy(cmd.GroupConfig); clause != "" {
 		stmt += " GROUP BY " + clause
 	}
 
-	if clause := f.FormatOrder(cmd.OrderConfig); clause != "" {
+	if clause, havingArgs := f.FormatWhere(ex.Where(cmd.Having)); clause != "" {
+		stmt += " HAVING " + clause
+		args = append(args, havingArgs...)
+	}
+
+	order := cmd.OrderConfig
+	if cmd.CursorConfig.Before != "" {
+		order = f.reverseOrder(order)
+	}
+
+	if clause := f.FormatOrder(order); clause != "" {
 		stmt += " ORDER BY " + clause
 	}
 
@@ -86,7 +112,7 @@ func (f *formatter) formatQueryWithPartition(cmd ex.Command, types map[string]st
 
 	partitionFields := strings.Join(cmd.PartitionConfig, ", ")
 
-	orderClause := "id"
+	orderClause := strings.Join(f.primaryKey(cmd), ", ")
 	if len(cmd.OrderConfig) > 0 {
 		orderClause = strings.Join(cmd.OrderConfig, ", ")
 	}
@@ -105,12 +131,18 @@ func (f *formatter) formatQueryWithPartition(cmd ex.Command, types map[string]st
 		}
 	}
 
+	joinClause := ""
+	if clause := f.FormatJoin(cmd.JoinConfig); clause != "" {
+		joinClause = " " + clause
+	}
+
 	subquery := fmt.Sprintf(
-		"SELECT %s, ROW_NUMBER() OVER (PARTITION BY %s ORDER BY %s) as rn FROM %s%s",
+		"SELECT %s, ROW_NUMBER() OVER (PARTITION BY %s ORDER BY %s) as rn FROM %s%s%s",
 		columns,
 		partitionFields,
 		orderClause,
 		cmd.Resource,
+		joinClause,
 		whereClause,
 	)
 
@@ -158,12 +190,16 @@ func (f *formatter) FormatInsert(cmd ex.Command, types map[string]string) ex.Sta
 
 	stmt = "INSERT INTO " + cmd.Resource
 
-	if columns, columnArgs := f.FormatValues(cmd.Values, types); columns != "" {
+	if len(cmd.RowsConfig) > 0 {
+		columns, rowArgs := f.FormatInsertRows(cmd.RowsConfig, types)
+		stmt += " " + columns
+		args = append(args, rowArgs...)
+	} else if columns, columnArgs := f.FormatValues(cmd.Values, types); columns != "" {
 		stmt += " SET " + columns
 		args = append(args, columnArgs...)
 	}
 
-	if clause := f.FormatConflict(cmd.OnConflictConfig); clause != "" {
+	if clause := f.FormatConflict(cmd.OnConflictConfig, f.primaryKey(cmd)); clause != "" {
 		stmt += " ON " + clause
 	}
 
@@ -255,11 +291,57 @@ func (f *formatter) FormatValues(values ex.Values, types map[string]string) (str
 	return strings.Join(columns, ","), args
 }
 
+func (f *formatter) FormatInsertRows(rows ex.RowsConfig, types map[string]string) (string, []any) {
+
+	keys := rows.Columns()
+
+	var tuples []string
+	var args []any
+
+	for _, row := range rows {
+		var placeholders []string
+		for _, k := range keys {
+			v, ok := row[k]
+			if !ok {
+				placeholders = append(placeholders, "DEFAULT")
+				continue
+			}
+			clause, arg := f.FormatValueArg(k, v, types[k])
+			placeholders = append(placeholders, strings.TrimPrefix(clause, k+" = "))
+			args = append(args, arg...)
+		}
+		tuples = append(tuples, "("+strings.Join(placeholders, ", ")+")")
+	}
+
+	return fmt.Sprintf("(%s) VALUES %s", strings.Join(keys, ", "), strings.Join(tuples, ",")), args
+}
+
 func (f *formatter) FormatColumns(columns []string) string {
 
 	return strings.Join(columns, ",")
 }
 
+func (f *formatter) FormatJoin(joins []ex.JoinClause) string {
+
+	var clauses []string
+
+	for _, join := range joins {
+		joinType := "INNER"
+		if join.Type != "" {
+			joinType = strings.ToUpper(join.Type)
+		}
+
+		clause := fmt.Sprintf("%s JOIN %s", joinType, join.Resource)
+		if len(join.On) > 0 {
+			clause += " ON " + strings.Join(join.On, " AND ")
+		}
+
+		clauses = append(clauses, clause)
+	}
+
+	return strings.Join(clauses, " ")
+}
+
 func (f *formatter) FormatGroupBy(groupBy []string) string {
 
 	return strings.Join(groupBy, ",")
@@ -270,6 +352,147 @@ func (f *formatter) FormatOrder(order []string) string {
 	return strings.Join(order, ",")
 }
 
+func (f *formatter) FormatPrimaryKey(resource string) ex.Statement {
+
+	return ex.Exec("SELECT COLUMN_NAME FROM information_schema.KEY_COLUMN_USAGE WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND CONSTRAINT_NAME = 'PRIMARY' ORDER BY ORDINAL_POSITION", resource)
+}
+
+func (f *formatter) FormatResourceNames() ex.Statement {
+
+	return ex.Exec("SELECT TABLE_NAME FROM information_schema.TABLES WHERE TABLE_SCHEMA = DATABASE() ORDER BY TABLE_NAME")
+}
+
+func (f *formatter) FormatColumnSchema(resource string) ex.Statement {
+
+	return ex.Exec("SELECT COLUMN_NAME, COLUMN_TYPE, IS_NULLABLE = 'YES' FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? ORDER BY ORDINAL_POSITION", resource)
+}
+
+func (f *formatter) FormatIndexSchema(resource string) ex.Statement {
+
+	return ex.Exec("SELECT INDEX_NAME, COLUMN_NAME, NON_UNIQUE = 0 FROM information_schema.STATISTICS WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND INDEX_NAME <> 'PRIMARY' AND COLUMN_NAME IS NOT NULL ORDER BY INDEX_NAME, SEQ_IN_INDEX", resource)
+}
+
+func (f *formatter) FormatForeignKeySchema(resource string) ex.Statement {
+
+	return ex.Exec("SELECT CONSTRAINT_NAME, COLUMN_NAME, REFERENCED_TABLE_NAME, REFERENCED_COLUMN_NAME FROM information_schema.KEY_COLUMN_USAGE WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND REFERENCED_TABLE_NAME IS NOT NULL ORDER BY CONSTRAINT_NAME, ORDINAL_POSITION", resource)
+}
+
+func (f *formatter) primaryKey(cmd ex.Command) []string {
+	if len(cmd.PrimaryKeyConfig) > 0 {
+		return cmd.PrimaryKeyConfig
+	}
+	return []string{"id"}
+}
+
+func (f *formatter) MaxPlaceholders() int {
+	return 65535
+}
+
+// FormatError classifies MySQL errors by their error number.
+func (f *formatter) FormatError(err error) error {
+
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) {
+		return err
+	}
+
+	switch mysqlErr.Number {
+	case 1062, 1048, 1451, 1452, 3819: // duplicate, null, foreign key, check
+		return ex.NewError(ex.ConstraintViolation, err)
+
+	case 1213:
+		return ex.NewError(ex.Deadlock, err)
+
+	case 1205, 3024: // lock wait, max execution time
+		return ex.NewError(ex.Timeout, err)
+
+	case 1040, 1203: // too many connections
+		return ex.NewError(ex.Unavailable, err)
+
+	case 1054, 1064, 1146, 1264, 1366, 1406: // unknown column, syntax, unknown table, bad value
+		return ex.NewError(ex.Invalid, err)
+
+	default:
+		return err
+	}
+}
+
+func (f *formatter) FormatCursor(cursor ex.CursorConfig, order []string) (string, []any) {
+
+	token, backward := cursor.After, false
+	if cursor.Before != "" {
+		token, backward = cursor.Before, true
+	}
+
+	if token == "" || len(order) == 0 {
+		return "", nil
+	}
+
+	values, err := ex.DecodeCursor(token)
+	if err != nil || len(values) != len(order) {
+		return "", nil
+	}
+
+	var args []any
+	placeholder := func(value any) string {
+		args = append(args, value)
+		return "?"
+	}
+
+	var columns []string
+	var ops []string
+	uniform := true
+
+	for _, o := range order {
+		column, desc := ex.ParseOrder(o)
+
+		op := ">"
+		if desc != backward {
+			op = "<"
+		}
+
+		columns = append(columns, column)
+		ops = append(ops, op)
+		uniform = uniform && op == ops[0]
+	}
+
+	if uniform {
+		var placeholders []string
+		for _, value := range values {
+			placeholders = append(placeholders, placeholder(value))
+		}
+		return fmt.Sprintf("(%s) %s (%s)", strings.Join(columns, ", "), ops[0], strings.Join(placeholders, ", ")), args
+	}
+
+	// Mixed directions can't use a row comparison, so expand it into
+	// (a > x OR (a = x AND b < y)) ...
+	var clauses []string
+	for i := range columns {
+		var parts []string
+		for j := 0; j < i; j++ {
+			parts = append(parts, fmt.Sprintf("%s = %s", columns[j], placeholder(values[j])))
+		}
+		parts = append(parts, fmt.Sprintf("%s %s %s", columns[i], ops[i], placeholder(values[i])))
+		clauses = append(clauses, "("+strings.Join(parts, " AND ")+")")
+	}
+
+	return "(" + strings.Join(clauses, " OR ") + ")", args
+}
+
+func (f *formatter) reverseOrder(order []string) []string {
+
+	var reversed []string
+	for _, o := range order {
+		if column, desc := ex.ParseOrder(o); desc {
+			reversed = append(reversed, column+" ASC")
+		} else {
+			reversed = append(reversed, column+" DESC")
+		}
+	}
+
+	return reversed
+}
+
 func (f *formatter) FormatLimit(limit int) string {
 	if limit > 0 {
 		return fmt.Sprintf("%v", limit)
@@ -286,7 +509,7 @@ func (f *formatter) FormatOffset(offset int) string {
 	}
 }
 
-func (f *formatter) FormatConflict(conflict ex.OnConflictConfig) string {
+func (f *formatter) FormatConflict(conflict ex.OnConflictConfig, key []string) string {
 
 	if conflict.Error != "" {
 		return ""
@@ -294,7 +517,7 @@ func (f *formatter) FormatConflict(conflict ex.OnConflictConfig) string {
 
 	if c := conflict.Ignore; c != "" {
 		if c == "true" {
-			return "DUPLICATE KEY UPDATE id = id"
+			return fmt.Sprintf("DUPLICATE KEY UPDATE %s = %s", key[0], key[0])
 		} else {
 			return fmt.Sprintf("DUPLICATE KEY UPDATE %s = %s", c, c)
 		}
@@ -347,6 +570,17 @@ func (f *formatter) FormatWhereArg(k string, v any) (string, []any) {
 		return fmt.Sprintf("%s BETWEEN ? AND ?", k), []any{value.Start, value.End}
 	case ex.NotBtwnArg:
 		return fmt.Sprintf("%s NOT BETWEEN ? AND ?", k), []any{value.Start, value.End}
+	case ex.AllArg:
+		return f.formatAll(k, value)
+	case ex.AndArg:
+		return f.formatGroup(value, " AND ")
+	case ex.OrArg:
+		return f.formatGroup(value, " OR ")
+	case ex.NotArg:
+		if clause, args := f.FormatWhere(value.Arg); clause != "" {
+			return fmt.Sprintf("NOT (%s)", clause), args
+		}
+		return "", nil
 	default:
 		return fmt.Sprintf("%s = ?", k), []any{value}
 	}
@@ -375,6 +609,50 @@ func (f *formatter) FormatWhere(where ex.Where) (string, []any) {
 	return strings.Join(columns, " AND "), args
 }
 
+func (f *formatter) formatAll(k string, all ex.AllArg) (string, []any) {
+
+	var clauses []string
+	var args []any
+
+	for _, v := range all {
+		clause, clauseArgs := f.FormatWhereArg(k, v)
+		if clause != "" {
+			clauses = append(clauses, clause)
+			args = append(args, clauseArgs...)
+		}
+	}
+
+	if len(clauses) > 1 {
+		return "(" + strings.Join(clauses, " AND ") + ")", args
+	}
+
+	return strings.Join(clauses, " AND "), args
+}
+
+func (f *formatter) formatGroup(conditions []ex.Where, sep string) (string, []any) {
+
+	var clauses []string
+	var args []any
+
+	for _, where := range conditions {
+		clause, whereArgs := f.FormatWhere(where)
+		if clause == "" {
+			continue
+		}
+		if len(where) > 1 {
+			clause = "(" + clause + ")"
+		}
+		clauses = append(clauses, clause)
+		args = append(args, whereArgs...)
+	}
+
+	if len(clauses) == 0 {
+		return "", nil
+	}
+
+	return "(" + strings.Join(clauses, sep) + ")", args
+}
+
 func (f *formatter) formatIn(args []any) string {
 	qs := strings.Repeat("?", len(args))
 	return strings.Join(strings.Split(qs, ""), ",")
@@ -1,6 +1,11 @@
 package xmysql_test
 
 import (
+	"encoding/json"
+	"errors"
+
+	"github.com/go-sql-driver/mysql"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
@@ -64,6 +69,76 @@ var _ = Describe("Formatter", func() {
 			})
 		})
 
+		Context("when the command has multiple predicates on a column", func() {
+			BeforeEach(func() {
+				cmd = ex.Query("resources", ex.Where{
+					"id":   ex.All(ex.Gt(1), ex.NotEq(5)),
+					"name": ex.All(ex.NotLike("a%")),
+				})
+			})
+
+			It("formats the command", func() {
+				Expect(stmt.Stmt).To(Equal("SELECT * FROM resources WHERE (id > ? AND id != ?) AND name NOT LIKE ?"))
+				Expect(stmt.Args).To(Equal([]any{1, 5, "a%"}))
+			})
+		})
+
+		Context("when the command has an or group", func() {
+			BeforeEach(func() {
+				cmd = ex.Query("resources", ex.Or(
+					ex.Where{"status": "active"},
+					ex.Where{"owner_id": 5},
+				))
+			})
+
+			It("formats the command", func() {
+				Expect(stmt.Stmt).To(Equal("SELECT * FROM resources WHERE (status = ? OR owner_id = ?)"))
+				Expect(stmt.Args).To(Equal([]any{"active", 5}))
+			})
+		})
+
+		Context("when the command has nested groups", func() {
+			BeforeEach(func() {
+				cmd = ex.Query("resources",
+					ex.Where{"key": "value"},
+					ex.Or(
+						ex.Where{"status": "active", "owner_id": ex.Gt(5)},
+						ex.Not(ex.Where{"name": ex.Like("%test%")}),
+					),
+				)
+			})
+
+			It("formats the command", func() {
+				Expect(stmt.Stmt).To(Equal("SELECT * FROM resources WHERE ((owner_id > ? AND status = ?) OR NOT (name LIKE ?)) AND key = ?"))
+				Expect(stmt.Args).To(Equal([]any{5, "active", "%test%", "value"}))
+			})
+		})
+
+		Context("when the command has another where", func() {
+			BeforeEach(func() {
+				cmd = ex.Query("resources", ex.Where{"key": "value"}, ex.Where{"status": "active"})
+			})
+
+			It("formats the command with the last where", func() {
+				Expect(stmt.Stmt).To(Equal("SELECT * FROM resources WHERE status = ?"))
+				Expect(stmt.Args).To(Equal([]any{"active"}))
+			})
+		})
+
+		Context("when the command has an and group", func() {
+			BeforeEach(func() {
+				cmd = ex.Query("resources", ex.And(
+					ex.Where{"key": "value"},
+					ex.Or(ex.Where{"status": "active"}, ex.Where{"status": "pending"}),
+				))
+			})
+
+			It("formats the command", func() {
+				Expect(stmt.Stmt).To(Equal("SELECT * FROM resources WHERE (key = ? AND (status = ? OR status = ?))"))
+				Expect(stmt.Args).To(Equal([]any{"value", "active", "pending"}))
+			})
+		})
+
 		Context("when the command has columns", func() {
 			BeforeEach(func() {
 				cmd = ex.Query("resources", ex.Columns("key"))
@@ -74,6 +149,48 @@ var _ = Describe("Formatter", func() {
 			})
 		})
 
+		Context("when the command has joins", func() {
+			BeforeEach(func() {
+				cmd = ex.Query("orders",
+					ex.Columns("orders.id", "customers.name"),
+					ex.Join("customers", "orders.customer_id = customers.id"),
+					ex.LeftJoin("addresses", "addresses.customer_id = customers.id", "addresses.primary = orders.primary"),
+					ex.Where{"customers.status": "active"},
+				)
+			})
+
+			It("formats the command", func() {
+				Expect(stmt.Stmt).To(Equal("SELECT orders.id,customers.name FROM orders INNER JOIN customers ON orders.customer_id = customers.id LEFT JOIN addresses ON addresses.customer_id = customers.id AND addresses.primary = orders.primary WHERE customers.status = ?"))
+				Expect(stmt.Args).To(ConsistOf("active"))
+			})
+		})
+
+		Context("when the command has aggregate columns", func() {
+			BeforeEach(func() {
+				cmd = ex.Query("orders",
+					ex.Columns("customer_id"),
+					ex.Count("id"),
+					ex.CountDistinct("product_id").As("products"),
+					ex.Sum("amount").As("total"),
+					ex.GroupBy("customer_id"),
+				)
+			})
+
+			It("formats the command", func() {
+				Expect(stmt.Stmt).To(Equal("SELECT customer_id,COUNT(id),COUNT(DISTINCT product_id) AS products,SUM(amount) AS total FROM orders GROUP BY customer_id"))
+			})
+		})
+
+		Context("when the command sets its columns again", func() {
+			BeforeEach(func() {
+				cmd = ex.Query("orders", ex.Columns("id"), ex.Columns("customer_id"), ex.Count("id"))
+			})
+
+			It("formats the command with the last columns", func() {
+				Expect(stmt.Stmt).To(Equal("SELECT customer_id,COUNT(id) FROM orders"))
+			})
+		})
+
 		Context("when the command has group by", func() {
 			BeforeEach(func() {
 				cmd = ex.Query("resources", ex.GroupBy("key"))
@@ -84,6 +201,23 @@ var _ = Describe("Formatter", func() {
 			})
 		})
 
+		Context("when the command has having", func() {
+			BeforeEach(func() {
+				cmd = ex.Query("orders",
+					ex.Columns("customer_id"),
+					ex.Count("id"),
+					ex.Where{"status": "paid"},
+					ex.GroupBy("customer_id"),
+					ex.Having{"COUNT(id)": ex.Gt(10), "SUM(amount)": ex.LtEq(500)},
+				)
+			})
+
+			It("formats the command", func() {
+				Expect(stmt.Stmt).To(Equal("SELECT customer_id,COUNT(id) FROM orders WHERE status = ? GROUP BY customer_id HAVING COUNT(id) > ? AND SUM(amount) <= ?"))
+				Expect(stmt.Args).To(Equal([]any{"paid", 10, 500}))
+			})
+		})
+
 		Context("when the command has order", func() {
 			BeforeEach(func() {
 				cmd = ex.Query("resources", ex.Order("key"))
@@ -114,6 +248,54 @@ var _ = Describe("Formatter", func() {
 			})
 		})
 
+		Context("when the command has an after cursor", func() {
+			BeforeEach(func() {
+				cursor, _ := ex.EncodeCursor(10)
+				cmd = ex.Query("resources", ex.Where{"status": "active"}, ex.Order("id"), ex.After(cursor), ex.Limit(2))
+			})
+
+			It("formats the command", func() {
+				Expect(stmt.Stmt).To(Equal("SELECT * FROM resources WHERE status = ? AND (id) > (?) ORDER BY id LIMIT 2"))
+				Expect(stmt.Args).To(Equal([]any{"active", json.Number("10")}))
+			})
+		})
+
+		Context("when the command has an after cursor with multiple columns", func() {
+			BeforeEach(func() {
+				cursor, _ := ex.EncodeCursor("2024-01-01", 10)
+				cmd = ex.Query("resources", ex.Order("created_at DESC", "id DESC"), ex.After(cursor))
+			})
+
+			It("formats the command", func() {
+				Expect(stmt.Stmt).To(Equal("SELECT * FROM resources WHERE (created_at, id) < (?, ?) ORDER BY created_at DESC,id DESC"))
+				Expect(stmt.Args).To(Equal([]any{"2024-01-01", json.Number("10")}))
+			})
+		})
+
+		Context("when the command has a cursor with mixed directions", func() {
+			BeforeEach(func() {
+				cursor, _ := ex.EncodeCursor("name", 10)
+				cmd = ex.Query("resources", ex.Order("name", "id DESC"), ex.After(cursor))
+			})
+
+			It("formats the command", func() {
+				Expect(stmt.Stmt).To(Equal("SELECT * FROM resources WHERE ((name > ?) OR (name = ? AND id < ?)) ORDER BY name,id DESC"))
+				Expect(stmt.Args).To(Equal([]any{"name", "name", json.Number("10")}))
+			})
+		})
+
+		Context("when the command has a before cursor", func() {
+			BeforeEach(func() {
+				cursor, _ := ex.EncodeCursor(10)
+				cmd = ex.Query("resources", ex.Order("id"), ex.Before(cursor), ex.Limit(2))
+			})
+
+			It("reverses the order", func() {
+				Expect(stmt.Stmt).To(Equal("SELECT * FROM resources WHERE (id) < (?) ORDER BY id DESC LIMIT 2"))
+				Expect(stmt.Args).To(Equal([]any{json.Number("10")}))
+			})
+		})
+
 		Context("when the command has partition by", func() {
 			BeforeEach(func() {
 				cmd = ex.Query("resources", ex.PartitionBy("user_id"), ex.Order("created_at"))
@@ -209,6 +391,16 @@ var _ = Describe("Formatter", func() {
 				Expect(stmt.Args).To(Equal([]any{true, 5}))
 			})
 		})
+
+		Context("when the command has partition by without order", func() {
+			BeforeEach(func() {
+				cmd = ex.Query("resources", ex.PartitionBy("user_id"), ex.PrimaryKey("tenant_id", "uuid"))
+			})
+
+			It("orders the partition by the primary key", func() {
+				Expect(stmt.Stmt).To(Equal("SELECT * FROM (SELECT *, ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY tenant_id, uuid) as rn FROM resources) AS ranked"))
+			})
+		})
 	})
 
 	Describe("DELETE", func() {
@@ -269,6 +461,23 @@ var _ = Describe("Formatter", func() {
 			Expect(stmt.Args).To(ConsistOf("value"))
 		})
 
+		Context("when the command has rows", func() {
+			BeforeEach(func() {
+				cmd = ex.Insert("resources",
+					ex.Rows(
+						ex.Values{"key": "value1", "name": "name1"},
+						ex.Values{"key": "value2"},
+					),
+					ex.OnConflictUpdate("key"),
+				)
+			})
+
+			It("formats a single statement filling missing columns with defaults", func() {
+				Expect(stmt.Stmt).To(Equal("INSERT INTO resources (key, name) VALUES (?, ?),(?, DEFAULT) ON DUPLICATE KEY UPDATE key = VALUES(key)"))
+				Expect(stmt.Args).To(Equal([]any{"value1", "name1", "value2"}))
+			})
+		})
+
 		Context("when the command is wrapped in ex.Json", func() {
 			BeforeEach(func() {
 				cmd = ex.Insert("resources",
@@ -377,6 +586,21 @@ var _ = Describe("Formatter", func() {
 			})
 		})
 
+		Context("when the command has conflict ignore and a primary key", func() {
+			BeforeEach(func() {
+				cmd = ex.Insert("resources",
+					ex.Values{"key": "value"},
+					ex.OnConflictIgnore("true"),
+					ex.PrimaryKey("uuid"),
+				)
+			})
+
+			It("formats the command using the primary key", func() {
+				Expect(stmt.Stmt).To(Equal("INSERT INTO resources SET key = ? ON DUPLICATE KEY UPDATE uuid = uuid"))
+				Expect(stmt.Args).To(ConsistOf("value"))
+			})
+		})
+
 		Context("when the command has conflict error", func() {
 			BeforeEach(func() {
 				cmd = ex.Insert("resources",
@@ -429,6 +653,20 @@ var _ = Describe("Formatter", func() {
 			})
 		})
 
+		Context("when the command has an or group", func() {
+			BeforeEach(func() {
+				cmd = ex.Update("resources", ex.Values{"key": "value"}, ex.Or(
+					ex.Where{"status": "active"},
+					ex.Where{"owner_id": 5},
+				))
+			})
+
+			It("formats the command", func() {
+				Expect(stmt.Stmt).To(Equal("UPDATE resources SET key = ? WHERE (status = ? OR owner_id = ?)"))
+				Expect(stmt.Args).To(Equal([]any{"value", "active", 5}))
+			})
+		})
+
 		Context("when the command has order", func() {
 			BeforeEach(func() {
 				cmd = ex.Update("resources", ex.Order("key"))
@@ -449,4 +687,32 @@ var _ = Describe("Formatter", func() {
 			})
 		})
 	})
+	Describe("FormatError", func() {
+		var formatError func(error) error
+
+		BeforeEach(func() {
+			formatError = formatter.(xsql.ErrorFormatter).FormatError
+		})
+
+		It("classifies constraint violations", func() {
+			err := formatError(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry"})
+			Expect(ex.CodeOf(err)).To(Equal(ex.ConstraintViolation))
+			Expect(errors.As(err, new(*mysql.MySQLError))).To(BeTrue())
+		})
+
+		It("classifies deadlocks", func() {
+			err := formatError(&mysql.MySQLError{Number: 1213})
+			Expect(ex.CodeOf(err)).To(Equal(ex.Deadlock))
+		})
+
+		It("classifies lock wait timeouts", func() {
+			err := formatError(&mysql.MySQLError{Number: 1205})
+			Expect(ex.CodeOf(err)).To(Equal(ex.Timeout))
+		})
+
+		It("leaves other errors alone", func() {
+			err := errors.New("nope")
+			Expect(formatError(err)).To(Equal(err))
+		})
+	})
 })
@@ -9,7 +9,7 @@ import (
 	"strings"
 	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	"github.com/reverted/ex"
 )
 
@@ -58,16 +58,41 @@ func (f *formatter) formatQuery(cmd ex.Command, types map[string]string) ex.Stat
 		stmt = "SELECT * FROM " + cmd.Resource
 	}
 
+	if clause := f.FormatJoin(cmd.JoinConfig); clause != "" {
+		stmt += " " + clause
+	}
+
+	var conditions []string
+
 	if clause, whereArgs := f.FormatWhere(cmd.Where, 1); clause != "" {
-		stmt += " WHERE " + clause
+		conditions = append(conditions, clause)
 		args = append(args, whereArgs...)
 	}
 
+	if clause, cursorArgs := f.FormatCursor(cmd.CursorConfig, cmd.OrderConfig, len(args)+1); clause != "" {
+		conditions = append(conditions, clause)
+		args = append(args, cursorArgs...)
+	}
+
+	if len(conditions) > 0 {
+		stmt += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
 	if clause := f.FormatGroupBy(cmd.GroupConfig); clause != "" {
 		stmt += " GROUP BY " + clause
 	}
 
-	if clause := f.FormatOrder(cmd.OrderConfig); clause != "" {
+	if clause, havingArgs := f.FormatWhere(ex.Where(cmd.Having), len(args)+1); clause != "" {
+		stmt += " HAVING " + clause
+		args = append(args, havingArgs...)
+	}
+
+	order := cmd.OrderConfig
+	if cmd.CursorConfig.Before != "" {
+		order = f.reverseOrder(order)
+	}
+
+	if clause := f.FormatOrder(order); clause != "" {
 		stmt += " ORDER BY " + clause
 	}
 
@@ -87,7 +112,7 @@ func (f *formatter) formatQueryWithPartition(cmd ex.Command, types map[string]st
 
 	partitionFields := strings.Join(cmd.PartitionConfig, ", ")
 
-	orderClause := "id"
+	orderClause := strings.Join(f.primaryKey(cmd), ", ")
 	if len(cmd.OrderConfig) > 0 {
 		orderClause = strings.Join(cmd.OrderConfig, ", ")
 	}
@@ -106,12 +131,18 @@ func (f *formatter) formatQueryWithPartition(cmd ex.Command, types map[string]st
 		}
 	}
 
+	joinClause := ""
+	if clause := f.FormatJoin(cmd.JoinConfig); clause != "" {
+		joinClause = " " + clause
+	}
+
 	subquery := fmt.Sprintf(
-		"SELECT %s, ROW_NUMBER() OVER (PARTITION BY %s ORDER BY %s) as rn FROM %s%s",
+		"SELECT %s, ROW_NUMBER() OVER (PARTITION BY %s ORDER BY %s) as rn FROM %s%s%s",
 		columns,
 		partitionFields,
 		orderClause,
 		cmd.Resource,
+		joinClause,
 		whereClause,
 	)
 
@@ -149,6 +180,10 @@ func (f *formatter) FormatDelete(cmd ex.Command, types map[string]string) ex.Sta
 		stmt += " LIMIT " + clause
 	}
 
+	if clause := f.FormatReturning(cmd.ReturningConfig); clause != "" {
+		stmt += " RETURNING " + clause
+	}
+
 	return ex.Exec(stmt, args...)
 }
 
@@ -159,15 +194,23 @@ func (f *formatter) FormatInsert(cmd ex.Command, types map[string]string) ex.Sta
 
 	stmt = "INSERT INTO " + cmd.Resource
 
-	if columns, columnArgs := f.FormatInsertValues(cmd.Values, 1, types); columns != "" {
+	if len(cmd.RowsConfig) > 0 {
+		columns, rowArgs := f.FormatInsertRows(cmd.RowsConfig, 1, types)
+		stmt += " " + columns
+		args = append(args, rowArgs...)
+	} else if columns, columnArgs := f.FormatInsertValues(cmd.Values, 1, types); columns != "" {
 		stmt += " " + columns
 		args = append(args, columnArgs...)
 	}
 
-	if clause := f.FormatConflict(cmd.OnConflictConfig); clause != "" {
+	if clause := f.FormatConflict(cmd.OnConflictConfig, f.primaryKey(cmd)); clause != "" {
 		stmt += " ON " + clause
 	}
 
+	if clause := f.FormatReturning(cmd.ReturningConfig); clause != "" {
+		stmt += " RETURNING " + clause
+	}
+
 	return ex.Exec(stmt, args...)
 }
 
@@ -196,6 +239,10 @@ func (f *formatter) FormatUpdate(cmd ex.Command, types map[string]string) ex.Sta
 		stmt += " LIMIT " + clause
 	}
 
+	if clause := f.FormatReturning(cmd.ReturningConfig); clause != "" {
+		stmt += " RETURNING " + clause
+	}
+
 	return ex.Exec(stmt, args...)
 }
 
@@ -283,11 +330,58 @@ func (f *formatter) FormatInsertValues(values ex.Values, index int, types map[st
 	return fmt.Sprintf("(%s) VALUES (%s)", columnsStr, placeholdersStr), args
 }
 
+func (f *formatter) FormatInsertRows(rows ex.RowsConfig, index int, types map[string]string) (string, []any) {
+
+	keys := rows.Columns()
+
+	var tuples []string
+	var args []any
+
+	for _, row := range rows {
+		var placeholders []string
+		for _, k := range keys {
+			v, ok := row[k]
+			if !ok {
+				placeholders = append(placeholders, "DEFAULT")
+				continue
+			}
+			clause, arg := f.FormatValueArg(index, k, v, types[k])
+			placeholders = append(placeholders, strings.TrimPrefix(clause, k+" = "))
+			args = append(args, arg...)
+			index += len(arg) // Increment index by the number of arguments used
+		}
+		tuples = append(tuples, "("+strings.Join(placeholders, ", ")+")")
+	}
+
+	return fmt.Sprintf("(%s) VALUES %s", strings.Join(keys, ", "), strings.Join(tuples, ",")), args
+}
+
 func (f *formatter) FormatColumns(columns []string) string {
 
 	return strings.Join(columns, ",")
 }
 
+func (f *formatter) FormatJoin(joins []ex.JoinClause) string {
+
+	var clauses []string
+
+	for _, join := range joins {
+		joinType := "INNER"
+		if join.Type != "" {
+			joinType = strings.ToUpper(join.Type)
+		}
+
+		clause := fmt.Sprintf("%s JOIN %s", joinType, join.Resource)
+		if len(join.On) > 0 {
+			clause += " ON " + strings.Join(join.On, " AND ")
+		}
+
+		clauses = append(clauses, clause)
+	}
+
+	return strings.Join(clauses, " ")
+}
+
 func (f *formatter) FormatGroupBy(groupBy []string) string {
 
 	return strings.Join(groupBy, ",")
@@ -298,6 +392,162 @@ func (f *formatter) FormatOrder(order []string) string {
 	return strings.Join(order, ",")
 }
 
+func (f *formatter) FormatCursor(cursor ex.CursorConfig, order []string, index int) (string, []any) {
+
+	token, backward := cursor.After, false
+	if cursor.Before != "" {
+		token, backward = cursor.Before, true
+	}
+
+	if token == "" || len(order) == 0 {
+		return "", nil
+	}
+
+	values, err := ex.DecodeCursor(token)
+	if err != nil || len(values) != len(order) {
+		return "", nil
+	}
+
+	var args []any
+	placeholder := func(value any) string {
+		args = append(args, value)
+		index++
+		return fmt.Sprintf("$%d", index-1)
+	}
+
+	var columns []string
+	var ops []string
+	uniform := true
+
+	for _, o := range order {
+		column, desc := ex.ParseOrder(o)
+
+		op := ">"
+		if desc != backward {
+			op = "<"
+		}
+
+		columns = append(columns, column)
+		ops = append(ops, op)
+		uniform = uniform && op == ops[0]
+	}
+
+	if uniform {
+		var placeholders []string
+		for _, value := range values {
+			placeholders = append(placeholders, placeholder(value))
+		}
+		return fmt.Sprintf("(%s) %s (%s)", strings.Join(columns, ", "), ops[0], strings.Join(placeholders, ", ")), args
+	}
+
+	// Mixed directions can't use a row comparison, so expand it into
+	// (a > x OR (a = x AND b < y)) ...
+	var clauses []string
+	for i := range columns {
+		var parts []string
+		for j := 0; j < i; j++ {
+			parts = append(parts, fmt.Sprintf("%s = %s", columns[j], placeholder(values[j])))
+		}
+		parts = append(parts, fmt.Sprintf("%s %s %s", columns[i], ops[i], placeholder(values[i])))
+		clauses = append(clauses, "("+strings.Join(parts, " AND ")+")")
+	}
+
+	return "(" + strings.Join(clauses, " OR ") + ")", args
+}
+
+func (f *formatter) reverseOrder(order []string) []string {
+
+	var reversed []string
+	for _, o := range order {
+		if column, desc := ex.ParseOrder(o); desc {
+			reversed = append(reversed, column+" ASC")
+		} else {
+			reversed = append(reversed, column+" DESC")
+		}
+	}
+
+	return reversed
+}
+
+func (f *formatter) FormatReturning(returning []string) string {
+
+	return strings.Join(returning, ",")
+}
+
+func (f *formatter) SupportsReturning() bool {
+	return true
+}
+
+func (f *formatter) FormatPrimaryKey(resource string) ex.Statement {
+
+	return ex.Exec("SELECT a.attname FROM pg_index i JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = ANY(i.indkey) WHERE i.indrelid = to_regclass($1) AND i.indisprimary ORDER BY array_position(i.indkey::int2[], a.attnum)", resource)
+}
+
+func (f *formatter) FormatResourceNames() ex.Statement {
+
+	return ex.Exec("SELECT table_name FROM information_schema.tables WHERE table_schema = current_schema() ORDER BY table_name")
+}
+
+func (f *formatter) FormatColumnSchema(resource string) ex.Statement {
+
+	return ex.Exec("SELECT a.attname, format_type(a.atttypid, a.atttypmod), NOT a.attnotnull FROM pg_attribute a WHERE a.attrelid = to_regclass($1) AND a.attnum > 0 AND NOT a.attisdropped ORDER BY a.attnum", resource)
+}
+
+func (f *formatter) FormatIndexSchema(resource string) ex.Statement {
+
+	return ex.Exec("SELECT c.relname, a.attname, i.indisunique FROM pg_index i JOIN pg_class c ON c.oid = i.indexrelid JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = ANY(i.indkey) WHERE i.indrelid = to_regclass($1) AND NOT i.indisprimary ORDER BY c.relname, array_position(i.indkey::int2[], a.attnum)", resource)
+}
+
+func (f *formatter) FormatForeignKeySchema(resource string) ex.Statement {
+
+	return ex.Exec("SELECT c.conname, a.attname, c.confrelid::regclass::text, r.attname FROM pg_constraint c CROSS JOIN LATERAL unnest(c.conkey, c.confkey) WITH ORDINALITY AS k(attnum, refnum, n) JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = k.attnum JOIN pg_attribute r ON r.attrelid = c.confrelid AND r.attnum = k.refnum WHERE c.conrelid = to_regclass($1) AND c.contype = 'f' ORDER BY c.conname, k.n", resource)
+}
+
+func (f *formatter) primaryKey(cmd ex.Command) []string {
+	if len(cmd.PrimaryKeyConfig) > 0 {
+		return cmd.PrimaryKeyConfig
+	}
+	return []string{"id"}
+}
+
+func (f *formatter) MaxPlaceholders() int {
+	return 65535
+}
+
+// FormatError classifies Postgres errors by their SQLSTATE code.
+func (f *formatter) FormatError(err error) error {
+
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+
+	switch pqErr.Code {
+	case "40001": // serialization failure
+		return ex.NewError(ex.Conflict, err)
+
+	case "40P01":
+		return ex.NewError(ex.Deadlock, err)
+
+	case "55P03", "57014": // lock not available, statement timeout
+		return ex.NewError(ex.Timeout, err)
+	}
+
+	switch pqErr.Code.Class() {
+	case "23": // integrity constraint violation
+		return ex.NewError(ex.ConstraintViolation, err)
+
+	case "22", "42": // data exception, syntax error or access rule violation
+		return ex.NewError(ex.Invalid, err)
+
+	case "08", "53", "57": // connection exception, insufficient resources, operator intervention
+		return ex.NewError(ex.Unavailable, err)
+
+	default:
+		return err
+	}
+}
+
 func (f *formatter) FormatLimit(limit int) string {
 	if limit > 0 {
 		return fmt.Sprintf("%v", limit)
@@ -314,7 +564,7 @@ func (f *formatter) FormatOffset(offset int) string {
 	}
 }
 
-func (f *formatter) FormatConflict(conflict ex.OnConflictConfig) string {
+func (f *formatter) FormatConflict(conflict ex.OnConflictConfig, key []string) string {
 
 	if conflict.Error != "" {
 		return ""
@@ -336,7 +586,7 @@ func (f *formatter) FormatConflict(conflict ex.OnConflictConfig) string {
 	case len(conflict.Constraint) > 0:
 		return fmt.Sprintf("CONFLICT (%s) DO NOTHING", strings.Join(conflict.Constraint, ","))
 	case len(columns) > 0:
-		return fmt.Sprintf("CONFLICT (id) DO UPDATE SET %s", strings.Join(columns, ","))
+		return fmt.Sprintf("CONFLICT (%s) DO UPDATE SET %s", strings.Join(key, ","), strings.Join(columns, ","))
 	default:
 		return ""
 	}
@@ -375,6 +625,17 @@ func (f *formatter) FormatWhereArg(index int, k string, v any) (string, []any) {
 		return fmt.Sprintf("%s BETWEEN $%d AND $%d", k, index, index+1), []any{value.Start, value.End}
 	case ex.NotBtwnArg:
 		return fmt.Sprintf("%s NOT BETWEEN $%d AND $%d", k, index, index+1), []any{value.Start, value.End}
+	case ex.AllArg:
+		return f.formatAll(index, k, value)
+	case ex.AndArg:
+		return f.formatGroup(index, value, " AND ")
+	case ex.OrArg:
+		return f.formatGroup(index, value, " OR ")
+	case ex.NotArg:
+		if clause, args := f.FormatWhere(value.Arg, index); clause != "" {
+			return fmt.Sprintf("NOT (%s)", clause), args
+		}
+		return "", nil
 	default:
 		return fmt.Sprintf("%s = $%d", k, index), []any{value}
 	}
@@ -404,6 +665,52 @@ func (f *formatter) FormatWhere(where ex.Where, index int) (string, []any) {
 	return strings.Join(columns, " AND "), args
 }
 
+func (f *formatter) formatAll(index int, k string, all ex.AllArg) (string, []any) {
+
+	var clauses []string
+	var args []any
+
+	for _, v := range all {
+		clause, clauseArgs := f.FormatWhereArg(index, k, v)
+		if clause != "" {
+			clauses = append(clauses, clause)
+			args = append(args, clauseArgs...)
+			index += len(clauseArgs) // Increment index by the number of arguments used
+		}
+	}
+
+	if len(clauses) > 1 {
+		return "(" + strings.Join(clauses, " AND ") + ")", args
+	}
+
+	return strings.Join(clauses, " AND "), args
+}
+
+func (f *formatter) formatGroup(index int, conditions []ex.Where, sep string) (string, []any) {
+
+	var clauses []string
+	var args []any
+
+	for _, where := range conditions {
+		clause, whereArgs := f.FormatWhere(where, index)
+		if clause == "" {
+			continue
+		}
+		if len(where) > 1 {
+			clause = "(" + clause + ")"
+		}
+		clauses = append(clauses, clause)
+		args = append(args, whereArgs...)
+		index += len(whereArgs) // Increment index by the number of arguments used
+	}
+
+	if len(clauses) == 0 {
+		return "", nil
+	}
+
+	return "(" + strings.Join(clauses, sep) + ")", args
+}
+
 func (f *formatter) formatIn(index int, args []any) string {
 	params := make([]string, len(args))
 	for i := range args {
@@ -1,6 +1,11 @@
 package xpg_test
 
 import (
+	"encoding/json"
+	"errors"
+
+	"github.com/lib/pq"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
@@ -64,6 +69,65 @@ var _ = Describe("Formatter", func() {
 			})
 		})
 
+		Context("when the command has multiple predicates on a column", func() {
+			BeforeEach(func() {
+				cmd = ex.Query("resources", ex.Where{
+					"id":   ex.All(ex.Gt(1), ex.NotEq(5)),
+					"name": ex.All(ex.NotLike("a%")),
+				})
+			})
+
+			It("formats the command", func() {
+				Expect(stmt.Stmt).To(Equal("SELECT * FROM resources WHERE (id > $1 AND id != $2) AND name NOT LIKE $3"))
+				Expect(stmt.Args).To(Equal([]any{1, 5, "a%"}))
+			})
+		})
+
+		Context("when the command has an or group", func() {
+			BeforeEach(func() {
+				cmd = ex.Query("resources", ex.Or(
+					ex.Where{"status": "active"},
+					ex.Where{"owner_id": 5},
+				))
+			})
+
+			It("formats the command", func() {
+				Expect(stmt.Stmt).To(Equal("SELECT * FROM resources WHERE (status = $1 OR owner_id = $2)"))
+				Expect(stmt.Args).To(Equal([]any{"active", 5}))
+			})
+		})
+
+		Context("when the command has nested groups", func() {
+			BeforeEach(func() {
+				cmd = ex.Query("resources",
+					ex.Where{"key": "value"},
+					ex.Or(
+						ex.Where{"status": "active", "owner_id": ex.Gt(5)},
+						ex.Not(ex.Where{"name": ex.Like("%test%")}),
+					),
+				)
+			})
+
+			It("formats the command", func() {
+				Expect(stmt.Stmt).To(Equal("SELECT * FROM resources WHERE ((owner_id > $1 AND status = $2) OR NOT (name LIKE $3)) AND key = $4"))
+				Expect(stmt.Args).To(Equal([]any{5, "active", "%test%", "value"}))
+			})
+		})
+
+		Context("when the command has an and group", func() {
+			BeforeEach(func() {
+				cmd = ex.Query("resources", ex.And(
+					ex.Where{"key": "value"},
+					ex.Or(ex.Where{"status": "active"}, ex.Where{"status": "pending"}),
+				))
+			})
+
+			It("formats the command", func() {
+				Expect(stmt.Stmt).To(Equal("SELECT * FROM resources WHERE (key = $1 AND (status = $2 OR status = $3))"))
+				Expect(stmt.Args).To(Equal([]any{"value", "active", "pending"}))
+			})
+		})
+
 		Context("when the command has columns", func() {
 			BeforeEach(func() {
 				cmd = ex.Query("resources", ex.Columns("key"))
@@ -74,6 +138,38 @@ var _ = Describe("Formatter", func() {
 			})
 		})
 
+		Context("when the command has joins", func() {
+			BeforeEach(func() {
+				cmd = ex.Query("orders",
+					ex.Columns("orders.id", "customers.name"),
+					ex.Join("customers", "orders.customer_id = customers.id"),
+					ex.LeftJoin("addresses", "addresses.customer_id = customers.id", "addresses.primary = orders.primary"),
+					ex.Where{"customers.status": "active"},
+				)
+			})
+
+			It("formats the command", func() {
+				Expect(stmt.Stmt).To(Equal("SELECT orders.id,customers.name FROM orders INNER JOIN customers ON orders.customer_id = customers.id LEFT JOIN addresses ON addresses.customer_id = customers.id AND addresses.primary = orders.primary WHERE customers.status = $1"))
+				Expect(stmt.Args).To(ConsistOf("active"))
+			})
+		})
+
+		Context("when the command has aggregate columns", func() {
+			BeforeEach(func() {
+				cmd = ex.Query("orders",
+					ex.Columns("customer_id"),
+					ex.Count("id"),
+					ex.CountDistinct("product_id").As("products"),
+					ex.Sum("amount").As("total"),
+					ex.GroupBy("customer_id"),
+				)
+			})
+
+			It("formats the command", func() {
+				Expect(stmt.Stmt).To(Equal("SELECT customer_id,COUNT(id),COUNT(DISTINCT product_id) AS products,SUM(amount) AS total FROM orders GROUP BY customer_id"))
+			})
+		})
+
 		Context("when the command has group by", func() {
 			BeforeEach(func() {
 				cmd = ex.Query("resources", ex.GroupBy("key"))
@@ -84,6 +180,23 @@ var _ = Describe("Formatter", func() {
 			})
 		})
 
+		Context("when the command has having", func() {
+			BeforeEach(func() {
+				cmd = ex.Query("orders",
+					ex.Columns("customer_id"),
+					ex.Count("id"),
+					ex.Where{"status": "paid"},
+					ex.GroupBy("customer_id"),
+					ex.Having{"COUNT(id)": ex.Gt(10), "SUM(amount)": ex.LtEq(500)},
+				)
+			})
+
+			It("formats the command", func() {
+				Expect(stmt.Stmt).To(Equal("SELECT customer_id,COUNT(id) FROM orders WHERE status = $1 GROUP BY customer_id HAVING COUNT(id) > $2 AND SUM(amount) <= $3"))
+				Expect(stmt.Args).To(Equal([]any{"paid", 10, 500}))
+			})
+		})
+
 		Context("when the command has order", func() {
 			BeforeEach(func() {
 				cmd = ex.Query("resources", ex.Order("key"))
@@ -114,6 +227,54 @@ var _ = Describe("Formatter", func() {
 			})
 		})
 
+		Context("when the command has an after cursor", func() {
+			BeforeEach(func() {
+				cursor, _ := ex.EncodeCursor(10)
+				cmd = ex.Query("resources", ex.Where{"status": "active"}, ex.Order("id"), ex.After(cursor), ex.Limit(2))
+			})
+
+			It("formats the command", func() {
+				Expect(stmt.Stmt).To(Equal("SELECT * FROM resources WHERE status = $1 AND (id) > ($2) ORDER BY id LIMIT 2"))
+				Expect(stmt.Args).To(Equal([]any{"active", json.Number("10")}))
+			})
+		})
+
+		Context("when the command has an after cursor with multiple columns", func() {
+			BeforeEach(func() {
+				cursor, _ := ex.EncodeCursor("2024-01-01", 10)
+				cmd = ex.Query("resources", ex.Order("created_at DESC", "id DESC"), ex.After(cursor))
+			})
+
+			It("formats the command", func() {
+				Expect(stmt.Stmt).To(Equal("SELECT * FROM resources WHERE (created_at, id) < ($1, $2) ORDER BY created_at DESC,id DESC"))
+				Expect(stmt.Args).To(Equal([]any{"2024-01-01", json.Number("10")}))
+			})
+		})
+
+		Context("when the command has a cursor with mixed directions", func() {
+			BeforeEach(func() {
+				cursor, _ := ex.EncodeCursor("name", 10)
+				cmd = ex.Query("resources", ex.Order("name", "id DESC"), ex.After(cursor))
+			})
+
+			It("formats the command", func() {
+				Expect(stmt.Stmt).To(Equal("SELECT * FROM resources WHERE ((name > $1) OR (name = $2 AND id < $3)) ORDER BY name,id DESC"))
+				Expect(stmt.Args).To(Equal([]any{"name", "name", json.Number("10")}))
+			})
+		})
+
+		Context("when the command has a before cursor", func() {
+			BeforeEach(func() {
+				cursor, _ := ex.EncodeCursor(10)
+				cmd = ex.Query("resources", ex.Order("id"), ex.Before(cursor), ex.Limit(2))
+			})
+
+			It("reverses the order", func() {
+				Expect(stmt.Stmt).To(Equal("SELECT * FROM resources WHERE (id) < ($1) ORDER BY id DESC LIMIT 2"))
+				Expect(stmt.Args).To(Equal([]any{json.Number("10")}))
+			})
+		})
+
 		Context("when the command has partition by", func() {
 			BeforeEach(func() {
 				cmd = ex.Query("resources", ex.PartitionBy("user_id"), ex.Order("created_at"))
@@ -209,6 +370,16 @@ var _ = Describe("Formatter", func() {
 				Expect(stmt.Args).To(Equal([]any{true, 5}))
 			})
 		})
+
+		Context("when the command has partition by without order", func() {
+			BeforeEach(func() {
+				cmd = ex.Query("resources", ex.PartitionBy("user_id"), ex.PrimaryKey("tenant_id", "uuid"))
+			})
+
+			It("orders the partition by the primary key", func() {
+				Expect(stmt.Stmt).To(Equal("SELECT * FROM (SELECT *, ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY tenant_id, uuid) as rn FROM resources) AS ranked"))
+			})
+		})
 	})
 
 	Describe("DELETE", func() {
@@ -255,6 +426,17 @@ var _ = Describe("Formatter", func() {
 				Expect(stmt.Stmt).To(Equal("DELETE FROM resources LIMIT 1"))
 			})
 		})
+
+		Context("when the command has returning", func() {
+			BeforeEach(func() {
+				cmd = ex.Delete("resources", ex.Where{"key": "value"}, ex.Returning())
+			})
+
+			It("formats the command", func() {
+				Expect(stmt.Stmt).To(Equal("DELETE FROM resources WHERE key = $1 RETURNING *"))
+				Expect(stmt.Args).To(ConsistOf("value"))
+			})
+		})
 	})
 
 	Describe("INSERT", func() {
@@ -269,6 +451,23 @@ var _ = Describe("Formatter", func() {
 			Expect(stmt.Args).To(ConsistOf("value"))
 		})
 
+		Context("when the command has rows", func() {
+			BeforeEach(func() {
+				cmd = ex.Insert("resources",
+					ex.Rows(
+						ex.Values{"key": "value1", "name": "name1"},
+						ex.Values{"key": "value2"},
+					),
+					ex.OnConflictUpdate("key"),
+				)
+			})
+
+			It("formats a single statement filling missing columns with defaults", func() {
+				Expect(stmt.Stmt).To(Equal("INSERT INTO resources (key, name) VALUES ($1, $2),($3, DEFAULT) ON CONFLICT (id) DO UPDATE SET key = EXCLUDED.key"))
+				Expect(stmt.Args).To(Equal([]any{"value1", "name1", "value2"}))
+			})
+		})
+
 		Context("when the command is wrapped in ex.Json", func() {
 			BeforeEach(func() {
 				cmd = ex.Insert("resources",
@@ -363,6 +562,21 @@ var _ = Describe("Formatter", func() {
 			})
 		})
 
+		Context("when the command has conflict update and a primary key", func() {
+			BeforeEach(func() {
+				cmd = ex.Insert("resources",
+					ex.Values{"key": "value"},
+					ex.OnConflictUpdate("key"),
+					ex.PrimaryKey("tenant_id", "uuid"),
+				)
+			})
+
+			It("formats the command with conflict on the primary key", func() {
+				Expect(stmt.Stmt).To(Equal("INSERT INTO resources (key) VALUES ($1) ON CONFLICT (tenant_id,uuid) DO UPDATE SET key = EXCLUDED.key"))
+				Expect(stmt.Args).To(ConsistOf("value"))
+			})
+		})
+
 		Context("when the command has conflict ignore", func() {
 			BeforeEach(func() {
 				cmd = ex.Insert("resources",
@@ -377,6 +591,21 @@ var _ = Describe("Formatter", func() {
 			})
 		})
 
+		Context("when the command has returning", func() {
+			BeforeEach(func() {
+				cmd = ex.Insert("resources",
+					ex.Values{"key": "value"},
+					ex.OnConflictIgnore("true"),
+					ex.Returning(),
+				)
+			})
+
+			It("formats the command", func() {
+				Expect(stmt.Stmt).To(Equal("INSERT INTO resources (key) VALUES ($1) ON CONFLICT DO NOTHING RETURNING *"))
+				Expect(stmt.Args).To(ConsistOf("value"))
+			})
+		})
+
 		Context("when the command has conflict error", func() {
 			BeforeEach(func() {
 				cmd = ex.Insert("resources",
@@ -429,6 +658,31 @@ var _ = Describe("Formatter", func() {
 			})
 		})
 
+		Context("when the command has an or group", func() {
+			BeforeEach(func() {
+				cmd = ex.Update("resources", ex.Values{"key": "value"}, ex.Or(
+					ex.Where{"status": "active"},
+					ex.Where{"owner_id": 5},
+				))
+			})
+
+			It("formats the command", func() {
+				Expect(stmt.Stmt).To(Equal("UPDATE resources SET key = $1 WHERE (status = $2 OR owner_id = $3)"))
+				Expect(stmt.Args).To(Equal([]any{"value", "active", 5}))
+			})
+		})
+
+		Context("when the command has returning columns", func() {
+			BeforeEach(func() {
+				cmd = ex.Update("resources", ex.Values{"key1": "value1"}, ex.Where{"key2": "value2"}, ex.Returning("id", "key1"))
+			})
+
+			It("formats the command", func() {
+				Expect(stmt.Stmt).To(Equal("UPDATE resources SET key1 = $1 WHERE key2 = $2 RETURNING id,key1"))
+				Expect(stmt.Args).To(ConsistOf("value1", "value2"))
+			})
+		})
+
 		Context("when the command has order", func() {
 			BeforeEach(func() {
 				cmd = ex.Update("resources", ex.Order("key"))
@@ -449,4 +703,37 @@ var _ = Describe("Formatter", func() {
 			})
 		})
 	})
+	Describe("FormatError", func() {
+		var formatError func(error) error
+
+		BeforeEach(func() {
+			formatError = formatter.(xsql.ErrorFormatter).FormatError
+		})
+
+		It("classifies constraint violations", func() {
+			err := formatError(&pq.Error{Code: "23505", Message: "duplicate key value"})
+			Expect(ex.CodeOf(err)).To(Equal(ex.ConstraintViolation))
+			Expect(errors.As(err, new(*pq.Error))).To(BeTrue())
+		})
+
+		It("classifies serialization failures", func() {
+			err := formatError(&pq.Error{Code: "40001"})
+			Expect(ex.CodeOf(err)).To(Equal(ex.Conflict))
+		})
+
+		It("classifies deadlocks", func() {
+			err := formatError(&pq.Error{Code: "40P01"})
+			Expect(ex.CodeOf(err)).To(Equal(ex.Deadlock))
+		})
+
+		It("classifies invalid statements", func() {
+			err := formatError(&pq.Error{Code: "42P01"})
+			Expect(ex.CodeOf(err)).To(Equal(ex.Invalid))
+		})
+
+		It("leaves other errors alone", func() {
+			err := errors.New("nope")
+			Expect(formatError(err)).To(Equal(err))
+		})
+	})
 })
@@ -1,9 +1,14 @@
 package ex
 
 import (
+	"bytes"
+	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"regexp"
+	"sort"
 	"strings"
 	"time"
 )
@@ -18,10 +23,25 @@ type Request interface {
 
 type Batch struct {
 	Requests []Request `json:"requests,omitempty"`
+	Partial  bool      `json:"partial,omitempty"`
+	Results  bool      `json:"results,omitempty"`
 }
 
 func (b Batch) exec() {}
 
+// Results passed as the result of a batch collects the result of each
+// request, skipping instructions, into the destination at its index. A
+// missing or nil destination discards the result of its request.
+type Results []any
+
+// BatchResult reports the outcome of a request in a partial batch. Index
+// counts the requests of the batch, skipping instructions.
+type BatchResult struct {
+	Index        int    `json:"index"`
+	Error        string `json:"error,omitempty"`
+	RowsAffected int64  `json:"rows_affected"`
+}
+
 type Instruction struct {
 	Stmt string `json:"stmt,omitempty"`
 }
@@ -35,18 +55,44 @@ type Statement struct {
 
 func (s Statement) exec() {}
 
+// RowIterator yields the rows of a Load. Next returns io.EOF once every
+// row has been read.
+type RowIterator interface {
+	Next() ([]any, error)
+}
+
+// StreamFunc receives each row of a result as it's read. Passing one as the
+// result of an exec streams the rows instead of collecting them in a slice.
+type StreamFunc func(row map[string]any) error
+
+type Load struct {
+	Resource string      `json:"resource,omitempty"`
+	Columns  []string    `json:"columns,omitempty"`
+	Rows     RowIterator `json:"-"`
+}
+
+func (l Load) exec() {}
+
 type Command struct {
-	Action           string           `json:"action,omitempty"`
-	Resource         string           `json:"resource,omitempty"`
-	Where            Where            `json:"where,omitempty"`
-	Values           Values           `json:"values,omitempty"`
-	ColumnConfig     ColumnConfig     `json:"columns,omitempty"`
-	PartitionConfig  PartitionConfig  `json:"partition,omitempty"`
-	GroupConfig      GroupConfig      `json:"group,omitempty"`
-	OrderConfig      OrderConfig      `json:"order,omitempty"`
-	LimitConfig      LimitConfig      `json:"limit,omitempty"`
-	OffsetConfig     OffsetConfig     `json:"offset,omitempty"`
-	OnConflictConfig OnConflictConfig `json:"on_conflict,omitempty"`
+	Action            string            `json:"action,omitempty"`
+	Resource          string            `json:"resource,omitempty"`
+	Where             Where             `json:"where,omitempty"`
+	Having            Having            `json:"having,omitempty"`
+	Values            Values            `json:"values,omitempty"`
+	RowsConfig        RowsConfig        `json:"rows,omitempty"`
+	ColumnConfig      ColumnConfig      `json:"columns,omitempty"`
+	JoinConfig        JoinConfig        `json:"join,omitempty"`
+	PartitionConfig   PartitionConfig   `json:"partition,omitempty"`
+	GroupConfig       GroupConfig       `json:"group,omitempty"`
+	OrderConfig       OrderConfig       `json:"order,omitempty"`
+	LimitConfig       LimitConfig       `json:"limit,omitempty"`
+	OffsetConfig      OffsetConfig      `json:"offset,omitempty"`
+	CursorConfig      CursorConfig      `json:"cursor,omitempty"`
+	TotalConfig       TotalConfig       `json:"total,omitempty"`
+	ReadPrimaryConfig ReadPrimaryConfig `json:"read_primary,omitempty"`
+	OnConflictConfig  OnConflictConfig  `json:"on_conflict,omitempty"`
+	ReturningConfig   ReturningConfig   `json:"returning,omitempty"`
+	PrimaryKeyConfig  PrimaryKeyConfig  `json:"primary_key,omitempty"`
 }
 
 func (c Command) exec() {}
@@ -55,9 +101,32 @@ func (w Where) MarshalJSON() ([]byte, error) {
 
 	fields := map[string]any{}
 	for k, v := range w {
-		key, value, err := FormatWhereArg(k, v)
-		if err == nil {
-			fields[key] = fmt.Sprintf("%v", value)
+		args, ok := v.(AllArg)
+		if !ok {
+			args = AllArg{v}
+		}
+
+		for _, arg := range args {
+			key, value, err := FormatWhereArg(k, arg)
+			if err != nil {
+				continue
+			}
+
+			var field any
+			if raw, ok := value.(json.RawMessage); ok {
+				field = raw
+			} else {
+				field = fmt.Sprintf("%v", value)
+			}
+
+			switch existing := fields[key].(type) {
+			case nil:
+				fields[key] = field
+			case []any:
+				fields[key] = append(existing, field)
+			default:
+				fields[key] = []any{existing, field}
+			}
 		}
 	}
 	return json.Marshal(fields)
@@ -74,6 +143,21 @@ func (w *Where) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+func (h Having) MarshalJSON() ([]byte, error) {
+	return Where(h).MarshalJSON()
+}
+
+func (h *Having) UnmarshalJSON(b []byte) error {
+
+	var contents map[string]any
+	err := json.Unmarshal(b, &contents)
+	if err != nil {
+		return err
+	}
+	*h = Having(parseWhere(contents))
+	return nil
+}
+
 func (w Values) MarshalJSON() ([]byte, error) {
 
 	fields := map[string]any{}
@@ -98,17 +182,50 @@ func (w *Values) UnmarshalJSON(b []byte) error {
 }
 
 func parseWhere(args map[string]any) map[string]any {
-	fields := map[string]any{}
-	for k, v := range args {
-		val, _ := v.(string)
-		key, value, err := ParseWhereArg(k, val)
-		if err == nil {
-			fields[key] = value
+
+	var keys []string
+	for k := range args {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	fields := Where{}
+	for _, k := range keys {
+		for _, val := range parseWhereValues(args[k]) {
+			key, value, err := ParseWhereArg(k, val)
+			if err == nil {
+				fields.Add(key, value)
+			}
 		}
 	}
 	return fields
 }
 
+func parseWhereValues(v any) []string {
+	switch value := v.(type) {
+	case string:
+		return []string{value}
+
+	case []any:
+		var vals []string
+		for _, item := range value {
+			val, ok := item.(string)
+			if !ok {
+				// not a list of repeated args, so treat it as a condition group
+				b, _ := json.Marshal(v)
+				return []string{string(b)}
+			}
+			vals = append(vals, val)
+		}
+		return vals
+
+	default:
+		// nested condition groups arrive as json arrays or objects
+		b, _ := json.Marshal(v)
+		return []string{string(b)}
+	}
+}
+
 func FormatWhereArg(k string, v any) (string, any, error) {
 	switch value := v.(type) {
 	case LiteralArg:
@@ -141,6 +258,17 @@ func FormatWhereArg(k string, v any) (string, any, error) {
 		return fmt.Sprintf("%s:btwn", k), formatArgs(value.Start, value.End), nil
 	case NotBtwnArg:
 		return fmt.Sprintf("%s:not_btwn", k), formatArgs(value.Start, value.End), nil
+	case AndArg:
+		b, err := json.Marshal([]Where(value))
+		return k, json.RawMessage(b), err
+	case OrArg:
+		b, err := json.Marshal([]Where(value))
+		return k, json.RawMessage(b), err
+	case NotArg:
+		b, err := json.Marshal(value.Arg)
+		return k, json.RawMessage(b), err
+	case AllArg:
+		return "", nil, errors.New("'all' args must be formatted individually")
 	default:
 		return k, v, nil
 	}
@@ -166,6 +294,12 @@ func formatArgs(args ...any) string {
 	return strings.Join(s, ",")
 }
 
+// WhereOperators are the operators ParseWhereArg accepts as a key:op suffix.
+var WhereOperators = []string{
+	"eq", "not_eq", "gt", "gt_eq", "lt", "lt_eq", "is", "is_not",
+	"like", "not_like", "in", "not_in", "btwn", "not_btwn",
+}
+
 func ParseWhereArg(k, v string) (string, any, error) {
 
 	p := strings.Split(k, ":")
@@ -203,6 +337,12 @@ func ParseWhereArg(k, v string) (string, any, error) {
 			return parseBtwn(key, v)
 		case "not_btwn":
 			return parseNotBtwn(key, v)
+		case "and":
+			return parseAnd(k, v)
+		case "or":
+			return parseOr(k, v)
+		case "not":
+			return parseNot(k, v)
 		}
 	}
 
@@ -241,6 +381,92 @@ func parseNotBtwn(k, v string) (string, any, error) {
 	return k, NotBtwn(parts[0], parts[1]), nil
 }
 
+func parseAnd(k, v string) (string, any, error) {
+	var and AndArg
+	if err := json.Unmarshal([]byte(v), &and); err != nil {
+		return "", nil, fmt.Errorf("unsupported 'and' args: %w", err)
+	}
+	return k, and, nil
+}
+
+func parseOr(k, v string) (string, any, error) {
+	var or OrArg
+	if err := json.Unmarshal([]byte(v), &or); err != nil {
+		return "", nil, fmt.Errorf("unsupported 'or' args: %w", err)
+	}
+	return k, or, nil
+}
+
+func parseNot(k, v string) (string, any, error) {
+	var not Where
+	if err := json.Unmarshal([]byte(v), &not); err != nil {
+		return "", nil, fmt.Errorf("unsupported 'not' args: %w", err)
+	}
+	return k, NotArg{not}, nil
+}
+
+var aggregateRegexp = regexp.MustCompile(`(?i)^(COUNT|SUM|AVG|MIN|MAX)\((DISTINCT\s+)?([^()\s]+)\)(?:\s+AS\s+(\w+))?$`)
+
+func ParseAggregate(column string) (AggregateArg, bool) {
+	matches := aggregateRegexp.FindStringSubmatch(strings.TrimSpace(column))
+	if len(matches) == 0 {
+		return AggregateArg{}, false
+	}
+
+	return AggregateArg{
+		Func:     strings.ToUpper(matches[1]),
+		Column:   matches[3],
+		Distinct: matches[2] != "",
+		Alias:    matches[4],
+	}, true
+}
+
+// ParseOrder splits an order entry such as "name DESC" into its column and
+// whether it is descending.
+func ParseOrder(order string) (string, bool) {
+	parts := strings.Fields(order)
+	if len(parts) == 0 {
+		return "", false
+	}
+
+	return parts[0], len(parts) > 1 && strings.EqualFold(parts[1], "DESC")
+}
+
+// EncodeCursor encodes the order column values of a row as an opaque token.
+func EncodeCursor(values ...any) (string, error) {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func DecodeCursor(cursor string) ([]any, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var values []any
+	if err := decoder.Decode(&values); err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+
+	return values, nil
+}
+
+// TxExecutor runs every request in one transaction until it's committed or
+// rolled back. Like Execute, Commit reports whether a failure can be retried.
+type TxExecutor interface {
+	Execute(context.Context, Request, any) (bool, error)
+	Commit() (bool, error)
+	Rollback() error
+}
+
 type Span interface {
 	Finish()
 }
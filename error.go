@@ -0,0 +1,59 @@
+package ex
+
+import "errors"
+
+type ErrorCode string
+
+const (
+	NotFound            ErrorCode = "not_found"
+	Conflict            ErrorCode = "conflict"
+	ConstraintViolation ErrorCode = "constraint_violation"
+	Invalid             ErrorCode = "invalid"
+	Unavailable         ErrorCode = "unavailable"
+	Deadlock            ErrorCode = "deadlock"
+	Timeout             ErrorCode = "timeout"
+)
+
+// Error classifies a failure, so callers can handle it without knowing
+// which driver or transport it came from.
+type Error struct {
+	Code    ErrorCode
+	Message string
+	Err     error
+}
+
+func NewError(code ErrorCode, err error) *Error {
+	return &Error{
+		Code:    code,
+		Message: err.Error(),
+		Err:     err,
+	}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Retryable reports whether the request could succeed if it was run again.
+func (e *Error) Retryable() bool {
+	switch e.Code {
+	case Conflict, Deadlock, Unavailable, Timeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// CodeOf returns the code of the first Error in the chain of err, or an
+// empty code when it has none.
+func CodeOf(err error) ErrorCode {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return ""
+}
@@ -1,5 +1,12 @@
 package ex
 
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
 func Query(resource string, opts ...Opt) Command {
 	return cmd(
 		"QUERY",
@@ -51,6 +58,41 @@ func Bulk(reqs ...Request) Batch {
 	}
 }
 
+// Partial runs each request of the batch in a savepoint. A failed request is
+// rolled back and reported in its BatchResult while the others still commit.
+func Partial(reqs ...Request) Batch {
+	return Batch{
+		Requests: reqs,
+		Partial:  true,
+	}
+}
+
+func BulkLoad(resource string, columns []string, rows RowIterator) Load {
+	return Load{
+		Resource: resource,
+		Columns:  columns,
+		Rows:     rows,
+	}
+}
+
+func IterateRows(rows ...[]any) RowIterator {
+	return &sliceIterator{rows: rows}
+}
+
+type sliceIterator struct {
+	rows [][]any
+}
+
+func (i *sliceIterator) Next() ([]any, error) {
+	if len(i.rows) == 0 {
+		return nil, io.EOF
+	}
+
+	row := i.rows[0]
+	i.rows = i.rows[1:]
+	return row, nil
+}
+
 type Opt interface {
 	opt(cmd *Command)
 }
@@ -60,6 +102,7 @@ func cmd(action, resource string, opts ...Opt) Command {
 		Action:           action,
 		Resource:         resource,
 		Where:            Where{},
+		Having:           Having{},
 		Values:           Values{},
 		OrderConfig:      nil,
 		LimitConfig:      LimitConfig(0),
@@ -78,7 +121,61 @@ func cmd(action, resource string, opts ...Opt) Command {
 type Where map[string]any
 
 func (w Where) opt(cmd *Command) {
-	cmd.Where = w
+	cmd.Where = mergeWhere(cmd.Where, w)
+}
+
+// mergeWhere adds the groups of And, Or and Not to the conditions. Any other
+// Where replaces them, as the last Where always has.
+func mergeWhere(existing, w Where) Where {
+	if len(existing) == 0 || !isGroup(existing) && !isGroup(w) {
+		return w
+	}
+
+	for k := range w {
+		if _, ok := existing[k]; ok {
+			return And(existing, w)
+		}
+	}
+
+	where := Where{}
+	for k, v := range existing {
+		where[k] = v
+	}
+	for k, v := range w {
+		where[k] = v
+	}
+	return where
+}
+
+func isGroup(w Where) bool {
+	for k := range w {
+		if k != ":and" && k != ":or" && k != ":not" {
+			return false
+		}
+	}
+	return len(w) > 0
+}
+
+// Add adds the value to key. If key already has a value, both are
+// combined using All so that neither predicate is lost.
+func (w Where) Add(key string, value any) {
+	existing, ok := w[key]
+	if !ok {
+		w[key] = value
+		return
+	}
+
+	if all, ok := existing.(AllArg); ok {
+		w[key] = append(all, value)
+	} else {
+		w[key] = All(existing, value)
+	}
+}
+
+type Having map[string]any
+
+func (h Having) opt(cmd *Command) {
+	cmd.Having = Having(mergeWhere(Where(cmd.Having), Where(h)))
 }
 
 type Values map[string]any
@@ -87,6 +184,36 @@ func (v Values) opt(cmd *Command) {
 	cmd.Values = v
 }
 
+func Rows(rows ...Values) Opt {
+	return RowsConfig(rows)
+}
+
+type RowsConfig []Values
+
+func (c RowsConfig) opt(cmd *Command) {
+	cmd.RowsConfig = append(cmd.RowsConfig, c...)
+}
+
+// Columns returns the sorted union of the columns set across all rows.
+func (c RowsConfig) Columns() []string {
+	seen := map[string]bool{}
+
+	var columns []string
+	for _, row := range c {
+		for k := range row {
+			if !seen[k] {
+				seen[k] = true
+				columns = append(columns, k)
+			}
+		}
+	}
+	sort.Strings(columns)
+
+	return columns
+}
+
+// Columns replaces the columns of the command, so aggregates are added after
+// it.
 func Columns(columns ...string) Opt {
 	return ColumnConfig(columns)
 }
@@ -97,6 +224,83 @@ func (c ColumnConfig) opt(cmd *Command) {
 	cmd.ColumnConfig = c
 }
 
+func Count(column string) AggregateArg {
+	return AggregateArg{Func: "COUNT", Column: column}
+}
+
+func CountDistinct(column string) AggregateArg {
+	return AggregateArg{Func: "COUNT", Column: column, Distinct: true}
+}
+
+func Sum(column string) AggregateArg {
+	return AggregateArg{Func: "SUM", Column: column}
+}
+
+func Avg(column string) AggregateArg {
+	return AggregateArg{Func: "AVG", Column: column}
+}
+
+func Min(column string) AggregateArg {
+	return AggregateArg{Func: "MIN", Column: column}
+}
+
+func Max(column string) AggregateArg {
+	return AggregateArg{Func: "MAX", Column: column}
+}
+
+type AggregateArg struct {
+	Func     string
+	Column   string
+	Distinct bool
+	Alias    string
+}
+
+func (a AggregateArg) As(alias string) AggregateArg {
+	a.Alias = alias
+	return a
+}
+
+func (a AggregateArg) String() string {
+	column := a.Column
+	if a.Distinct {
+		column = "DISTINCT " + column
+	}
+
+	expr := fmt.Sprintf("%s(%s)", strings.ToUpper(a.Func), column)
+	if a.Alias != "" {
+		expr += " AS " + a.Alias
+	}
+	return expr
+}
+
+func (a AggregateArg) opt(cmd *Command) {
+	cmd.ColumnConfig = append(cmd.ColumnConfig, a.String())
+}
+
+func Join(resource string, on ...string) Opt {
+	return JoinConfig{{Type: "INNER", Resource: resource, On: on}}
+}
+
+func InnerJoin(resource string, on ...string) Opt {
+	return JoinConfig{{Type: "INNER", Resource: resource, On: on}}
+}
+
+func LeftJoin(resource string, on ...string) Opt {
+	return JoinConfig{{Type: "LEFT", Resource: resource, On: on}}
+}
+
+type JoinConfig []JoinClause
+
+type JoinClause struct {
+	Type     string   `json:"type,omitempty"`
+	Resource string   `json:"resource,omitempty"`
+	On       []string `json:"on,omitempty"`
+}
+
+func (c JoinConfig) opt(cmd *Command) {
+	cmd.JoinConfig = append(cmd.JoinConfig, c...)
+}
+
 func Group(grouping ...string) Opt {
 	return GroupConfig(grouping)
 }
@@ -181,6 +385,74 @@ func OnConflictError(err string) Opt {
 	return OnConflictConfig{Error: err}
 }
 
+func Returning(columns ...string) Opt {
+	if len(columns) == 0 {
+		return ReturningConfig{"*"}
+	}
+	return ReturningConfig(columns)
+}
+
+type ReturningConfig []string
+
+func (c ReturningConfig) opt(cmd *Command) {
+	cmd.ReturningConfig = c
+}
+
+func PrimaryKey(columns ...string) Opt {
+	return PrimaryKeyConfig(columns)
+}
+
+type PrimaryKeyConfig []string
+
+func (c PrimaryKeyConfig) opt(cmd *Command) {
+	cmd.PrimaryKeyConfig = c
+}
+
+// After pages forward from a cursor returned in Meta.NextCursor of a forward
+// page, or in Meta.PrevCursor of a backward one.
+func After(cursor string) Opt {
+	return CursorConfig{After: cursor}
+}
+
+// Before pages backward from a cursor returned in Meta.PrevCursor of a
+// forward page, or in Meta.NextCursor of a backward one.
+func Before(cursor string) Opt {
+	return CursorConfig{Before: cursor}
+}
+
+type CursorConfig struct {
+	After  string `json:"after,omitempty"`
+	Before string `json:"before,omitempty"`
+}
+
+func (c CursorConfig) opt(cmd *Command) {
+	cmd.CursorConfig = c
+}
+
+// WithTotal counts every row matching the query, ignoring its limit, offset
+// and cursor, and reports it in Meta.Total.
+func WithTotal() Opt {
+	return TotalConfig(true)
+}
+
+type TotalConfig bool
+
+func (c TotalConfig) opt(cmd *Command) {
+	cmd.TotalConfig = c
+}
+
+// ReadPrimary sends a query to the primary connection instead of a replica,
+// so it sees writes that haven't replicated yet.
+func ReadPrimary() Opt {
+	return ReadPrimaryConfig(true)
+}
+
+type ReadPrimaryConfig bool
+
+func (c ReadPrimaryConfig) opt(cmd *Command) {
+	cmd.ReadPrimaryConfig = c
+}
+
 func Partition(fields ...string) Opt {
 	return PartitionConfig(fields)
 }
@@ -319,4 +591,30 @@ type JsonArg struct {
 	Arg any
 }
 
+func All(args ...any) AllArg {
+	return AllArg(args)
+}
+
+type AllArg []any
+
+func And(conditions ...Where) Where {
+	return Where{":and": AndArg(conditions)}
+}
+
+type AndArg []Where
+
+func Or(conditions ...Where) Where {
+	return Where{":or": OrArg(conditions)}
+}
+
+type OrArg []Where
+
+func Not(condition Where) Where {
+	return Where{":not": NotArg{condition}}
+}
+
+type NotArg struct {
+	Arg Where
+}
+
 var Null = Literal("NULL")
@@ -0,0 +1,31 @@
+package ex
+
+import "context"
+
+type metaKey struct{}
+
+// Meta collects response metadata that doesn't fit in the result rows.
+type Meta struct {
+	NextCursor string
+
+	// PrevCursor turns back from a page queried with a cursor: pass it to
+	// Before when paging with After, and to After when paging with Before
+	PrevCursor string
+
+	Total *int64
+
+	// RowsAffected counts the rows a write matched on postgres, but only the
+	// rows it changed on mysql unless the DSN sets clientFoundRows=true
+	RowsAffected *int64
+}
+
+// WithMeta returns a context that executors report metadata into.
+func WithMeta(ctx context.Context, meta *Meta) context.Context {
+	return context.WithValue(ctx, metaKey{}, meta)
+}
+
+// MetaFromContext returns the Meta registered with WithMeta, or nil.
+func MetaFromContext(ctx context.Context) *Meta {
+	meta, _ := ctx.Value(metaKey{}).(*Meta)
+	return meta
+}
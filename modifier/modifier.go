@@ -78,6 +78,12 @@ func (i *interceptor) Intercept(ctx context.Context, cmd ex.Command) (ex.Command
 				cmd.Values[key] = ctx.Value(key)
 			}
 		}
+
+		for _, row := range cmd.RowsConfig {
+			if _, ok := row[key]; !ok {
+				row[key] = ctx.Value(key)
+			}
+		}
 	}
 
 	return cmd, nil
@@ -171,6 +171,20 @@ var _ = Describe("Interceptor", func() {
 					Expect(res.Values).To(HaveKeyWithValue("some-other-key", "value"))
 				})
 			})
+
+			Context("when the cmd has rows", func() {
+				BeforeEach(func() {
+					cmd = ex.Insert("some-resource", ex.Rows(ex.Values{"some-key": "existing"}, ex.Values{}))
+
+					ctx = context.WithValue(ctx, "some-key", "value")
+					ctx = context.WithValue(ctx, "some-other-key", "value")
+				})
+
+				It("udpates every row without overwriting existing values", func() {
+					Expect(res.RowsConfig[0]).To(Equal(ex.Values{"some-key": "existing", "some-other-key": "value"}))
+					Expect(res.RowsConfig[1]).To(Equal(ex.Values{"some-key": "value", "some-other-key": "value"}))
+				})
+			})
 		})
 	})
 })
@@ -0,0 +1,35 @@
+package ex
+
+// Schema describes the columns of a resource and the keys and indexes over
+// them.
+type Schema struct {
+	Resource    string       `json:"resource"`
+	Columns     []Column     `json:"columns"`
+	PrimaryKey  []string     `json:"primary_key"`
+	Indexes     []Index      `json:"indexes"`
+	ForeignKeys []ForeignKey `json:"foreign_keys"`
+
+	// ColumnPatterns match the expressions, such as json paths, that can be
+	// used in place of a column
+	ColumnPatterns []string `json:"column_patterns,omitempty"`
+}
+
+type Column struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Nullable bool   `json:"nullable"`
+}
+
+type Index struct {
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"`
+	Unique  bool     `json:"unique"`
+}
+
+// ForeignKey references the ReferencedColumns of another resource.
+type ForeignKey struct {
+	Name              string   `json:"name"`
+	Columns           []string `json:"columns"`
+	Resource          string   `json:"resource"`
+	ReferencedColumns []string `json:"referenced_columns"`
+}
@@ -0,0 +1,204 @@
+package server
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func NewJsonEncoder() *jsonEncoder {
+	return &jsonEncoder{}
+}
+
+type jsonEncoder struct{}
+
+func (e *jsonEncoder) ContentType() string {
+	return "application/json"
+}
+
+func (e *jsonEncoder) NewRowEncoder(w io.Writer) RowEncoder {
+	return &jsonRowEncoder{w: w}
+}
+
+type jsonRowEncoder struct {
+	w       io.Writer
+	started bool
+}
+
+func (e *jsonRowEncoder) Encode(row map[string]any) error {
+
+	delim := ","
+	if !e.started {
+		e.started = true
+		delim = "["
+	}
+
+	if _, err := io.WriteString(e.w, delim); err != nil {
+		return err
+	}
+
+	return json.NewEncoder(e.w).Encode(row)
+}
+
+func (e *jsonRowEncoder) Close() error {
+
+	if !e.started {
+		_, err := io.WriteString(e.w, "[]\n")
+		return err
+	}
+
+	_, err := io.WriteString(e.w, "]\n")
+	return err
+}
+
+func NewNdjsonEncoder() *ndjsonEncoder {
+	return &ndjsonEncoder{}
+}
+
+type ndjsonEncoder struct{}
+
+func (e *ndjsonEncoder) ContentType() string {
+	return "application/x-ndjson"
+}
+
+func (e *ndjsonEncoder) NewRowEncoder(w io.Writer) RowEncoder {
+	return &ndjsonRowEncoder{json.NewEncoder(w)}
+}
+
+type ndjsonRowEncoder struct {
+	encoder *json.Encoder
+}
+
+func (e *ndjsonRowEncoder) Encode(row map[string]any) error {
+	return e.encoder.Encode(row)
+}
+
+func (e *ndjsonRowEncoder) Close() error {
+	return nil
+}
+
+func NewCsvEncoder() *csvEncoder {
+	return &csvEncoder{}
+}
+
+// csvEncoder writes a header row with the sorted columns of the first row.
+// Nested values are written as json and nulls as empty fields.
+type csvEncoder struct{}
+
+func (e *csvEncoder) ContentType() string {
+	return "text/csv"
+}
+
+func (e *csvEncoder) NewRowEncoder(w io.Writer) RowEncoder {
+	return &csvRowEncoder{w: csv.NewWriter(w)}
+}
+
+type csvRowEncoder struct {
+	w       *csv.Writer
+	columns []string
+}
+
+func (e *csvRowEncoder) Encode(row map[string]any) error {
+
+	if e.columns == nil {
+		for column := range row {
+			e.columns = append(e.columns, column)
+		}
+		sort.Strings(e.columns)
+
+		if err := e.w.Write(e.columns); err != nil {
+			return err
+		}
+	}
+
+	record := make([]string, len(e.columns))
+	for i, column := range e.columns {
+		field, err := csvField(row[column])
+		if err != nil {
+			return err
+		}
+		record[i] = field
+	}
+
+	return e.w.Write(record)
+}
+
+func (e *csvRowEncoder) Close() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func csvField(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case time.Time:
+		return v.Format(time.RFC3339Nano), nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, json.Number:
+		return fmt.Sprint(v), nil
+	default:
+		data, err := json.Marshal(v)
+		return string(data), err
+	}
+}
+
+// negotiate picks the encoder for the most preferred media type in the
+// Accept header, defaulting to json when the header is missing or allows
+// any type.
+func (s *server) negotiate(r *http.Request) (Encoder, error) {
+
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		return s.Encoders["application/json"], nil
+	}
+
+	var encoder Encoder
+	var quality float64
+
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if param, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(param, 64); err != nil {
+				continue
+			}
+		}
+
+		if q <= quality {
+			continue
+		}
+
+		switch mediaType {
+		case "*/*", "application/*":
+			encoder, quality = s.Encoders["application/json"], q
+
+		default:
+			if e, ok := s.Encoders[mediaType]; ok {
+				encoder, quality = e, q
+			}
+		}
+	}
+
+	if encoder == nil {
+		return nil, NewStatusError(http.StatusNotAcceptable, fmt.Errorf("unsupported accept: %s", accept))
+	}
+
+	return encoder, nil
+}
@@ -0,0 +1,94 @@
+package server_test
+
+import (
+	"bytes"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/reverted/ex/server"
+)
+
+var _ = Describe("Encoder", func() {
+
+	var (
+		err error
+		buf *bytes.Buffer
+
+		rows    []map[string]any
+		encoder server.Encoder
+	)
+
+	BeforeEach(func() {
+		buf = &bytes.Buffer{}
+	})
+
+	JustBeforeEach(func() {
+		enc := encoder.NewRowEncoder(buf)
+		for _, row := range rows {
+			err = enc.Encode(row)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		err = enc.Close()
+	})
+
+	Describe("json", func() {
+		BeforeEach(func() {
+			encoder = server.NewJsonEncoder()
+		})
+
+		Context("when there are no rows", func() {
+			BeforeEach(func() {
+				rows = nil
+			})
+
+			It("encodes an empty array", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(buf.String()).To(MatchJSON(`[]`))
+			})
+		})
+
+		Context("when there are rows", func() {
+			BeforeEach(func() {
+				rows = []map[string]any{{"id": 1}, {"id": 2}}
+			})
+
+			It("encodes an array", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(buf.String()).To(MatchJSON(`[{"id": 1}, {"id": 2}]`))
+			})
+		})
+	})
+
+	Describe("ndjson", func() {
+		BeforeEach(func() {
+			encoder = server.NewNdjsonEncoder()
+			rows = []map[string]any{{"id": 1}, {"id": 2}}
+		})
+
+		It("encodes a line per row", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(buf.String()).To(Equal("{\"id\":1}\n{\"id\":2}\n"))
+		})
+	})
+
+	Describe("csv", func() {
+		BeforeEach(func() {
+			encoder = server.NewCsvEncoder()
+			rows = []map[string]any{
+				{"name": "first, last", "id": 1, "tags": []any{"a"}, "deleted_at": nil},
+				{"name": "second", "id": 2, "tags": nil, "deleted_at": time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+			}
+		})
+
+		It("encodes a header and a record per row", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(buf.String()).To(Equal("" +
+				"deleted_at,id,name,tags\n" +
+				",1,\"first, last\",\"[\"\"a\"\"]\"\n" +
+				"2024-01-02T03:04:05Z,2,second,\n",
+			))
+		})
+	})
+})
@@ -0,0 +1,379 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/reverted/ex"
+)
+
+// ResourceLister lists the resources a Describer can describe.
+type ResourceLister interface {
+	Resources(context.Context) ([]string, error)
+}
+
+// WithOpenAPIInfo sets the title and version of the OpenAPI document.
+func WithOpenAPIInfo(title, version string) opt {
+	return func(s *server) {
+		s.OpenAPITitle = title
+		s.OpenAPIVersion = version
+	}
+}
+
+func (s *server) serveOpenAPI(w http.ResponseWriter, r *http.Request) {
+
+	doc, err := s.openAPI(r)
+	if err != nil {
+		s.Logger.Error(err)
+		s.writeError(w, r, err)
+		return
+	}
+
+	s.Logger.Infof("<<< %v : %v [200]", r.Method, r.URL)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(doc)
+}
+
+// openAPI builds an OpenAPI 3 document from the schemas of the resources
+// and the operators and headers the parser supports.
+func (s *server) openAPI(r *http.Request) (map[string]any, error) {
+
+	if r.Method != "GET" {
+		return nil, NewStatusError(http.StatusMethodNotAllowed, errors.New("unsupported method '"+r.Method+"'"))
+	}
+
+	lister, ok := s.Describer.(ResourceLister)
+	if !ok {
+		return nil, NewStatusError(http.StatusNotImplemented, errors.New("openapi is not supported"))
+	}
+
+	ctx := r.Context()
+
+	resources, err := lister.Resources(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := map[string]any{}
+	schemas := map[string]any{
+		"ex.Error": errorSchema(),
+	}
+
+	var described []string
+
+	for _, resource := range resources {
+
+		// Resources the interceptors reject are left out of the document
+		resource, err := s.interceptResource(ctx, resource)
+		if err != nil {
+			continue
+		}
+
+		schema, err := s.Describer.Describe(ctx, resource)
+		if err != nil {
+			return nil, err
+		}
+
+		schemas[resource] = resourceSchema(schema)
+		paths["/"+resource] = s.resourcePath(schema)
+		described = append(described, resource)
+	}
+
+	if len(described) > 0 {
+		paths["/:schema/{resource}"] = schemaPath(described)
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   s.OpenAPITitle,
+			"version": s.OpenAPIVersion,
+		},
+		"servers": []any{
+			map[string]any{"url": path.Dir(r.URL.Path)},
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": schemas,
+			"responses": map[string]any{
+				"Error": map[string]any{
+					"description": "The request failed",
+					"content": map[string]any{
+						"application/json": map[string]any{"schema": ref("ex.Error")},
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+func (s *server) resourcePath(schema ex.Schema) map[string]any {
+
+	item := map[string]any{}
+
+	for _, method := range []string{"GET", "POST", "PUT", "DELETE"} {
+
+		var params []any
+
+		if method != "POST" {
+			params = append(params, filterParams(schema)...)
+		}
+
+		params = append(params, headerParams(method, schema)...)
+
+		if s.Transactor != nil {
+			params = append(params, map[string]any{
+				"name":        "X-Transaction",
+				"in":          "header",
+				"description": "Id of the transaction to run in, from :begin",
+				"schema":      map[string]any{"type": "string"},
+			})
+		}
+
+		op := map[string]any{
+			"operationId": strings.ToLower(method) + "_" + schema.Resource,
+			"parameters":  params,
+			"responses": map[string]any{
+				"200":     s.rowsResponse(method, schema.Resource),
+				"default": map[string]any{"$ref": "#/components/responses/Error"},
+			},
+		}
+
+		if method == "GET" {
+			op["description"] = "Prefix a filter with having. to filter the grouped rows."
+		}
+
+		if body := s.requestBody(method, schema.Resource); body != nil {
+			op["requestBody"] = body
+		}
+
+		item[strings.ToLower(method)] = op
+	}
+
+	return item
+}
+
+func filterParams(schema ex.Schema) []any {
+
+	params := []any{}
+
+	for _, column := range schema.Columns {
+		params = append(params, map[string]any{
+			"name":        column.Name,
+			"in":          "query",
+			"description": "Filters on " + column.Name + ". Other operators are appended to the name as " + column.Name + ":<op>, one of " + strings.Join(ex.WhereOperators, ", "),
+			"schema":      map[string]any{"type": "string"},
+			"x-operators": ex.WhereOperators,
+		})
+	}
+
+	for _, group := range []string{":and", ":or", ":not"} {
+		params = append(params, map[string]any{
+			"name":        group,
+			"in":          "query",
+			"description": "JSON encoded list of conditions, which can nest further groups",
+			"schema":      map[string]any{"type": "string"},
+		})
+	}
+
+	return params
+}
+
+func headerParams(method string, schema ex.Schema) []any {
+
+	params := []any{}
+
+	for _, h := range commandHeaders[method] {
+		description := h.Description
+		if h.Columns && len(schema.ColumnPatterns) > 0 {
+			description += ". Columns can also be expressions matching " + strings.Join(schema.ColumnPatterns, ", ")
+		}
+
+		params = append(params, map[string]any{
+			"name":        h.Name,
+			"in":          "header",
+			"description": description,
+			"schema":      map[string]any{"type": h.Type},
+		})
+	}
+
+	return params
+}
+
+func (s *server) rowsResponse(method, resource string) map[string]any {
+
+	content := map[string]any{}
+	for contentType := range s.Encoders {
+		if contentType == "text/csv" {
+			content[contentType] = map[string]any{"schema": map[string]any{"type": "string"}}
+		} else {
+			content[contentType] = map[string]any{"schema": map[string]any{"type": "array", "items": ref(resource)}}
+		}
+	}
+
+	headers := map[string]any{}
+	if method == "GET" {
+		headers["X-Next-Cursor"] = map[string]any{
+			"description": "Cursor of the next page, when the page is full",
+			"schema":      map[string]any{"type": "string"},
+		}
+		headers["X-Prev-Cursor"] = map[string]any{
+			"description": "Cursor to turn back from a page queried with X-Cursor",
+			"schema":      map[string]any{"type": "string"},
+		}
+		headers["X-Total-Count"] = map[string]any{
+			"description": "Number of matching rows, with X-Total",
+			"schema":      map[string]any{"type": "integer"},
+		}
+	} else {
+		headers["X-Rows-Affected"] = map[string]any{
+			"description": "Number of rows the request affected",
+			"schema":      map[string]any{"type": "integer"},
+		}
+	}
+
+	return map[string]any{
+		"description": "The rows of " + resource,
+		"headers":     headers,
+		"content":     content,
+	}
+}
+
+func (s *server) requestBody(method, resource string) map[string]any {
+
+	switch method {
+	case "POST":
+		content := map[string]any{
+			"application/json": map[string]any{"schema": map[string]any{
+				"oneOf": []any{ref(resource), map[string]any{"type": "array", "items": ref(resource)}},
+			}},
+		}
+
+		// Bulk loads bypass interceptors, so they're rejected when there are any
+		if len(s.Interceptors) == 0 {
+			content["application/x-ndjson"] = map[string]any{"schema": map[string]any{"type": "string"}}
+			content["text/csv"] = map[string]any{"schema": map[string]any{"type": "string"}}
+		}
+
+		return map[string]any{"required": true, "content": content}
+
+	case "PUT":
+		return map[string]any{"required": true, "content": map[string]any{
+			"application/json": map[string]any{"schema": ref(resource)},
+		}}
+
+	default:
+		return nil
+	}
+}
+
+func schemaPath(resources []string) map[string]any {
+	return map[string]any{
+		"get": map[string]any{
+			"operationId": "get_schema",
+			"parameters": []any{
+				map[string]any{
+					"name":     "resource",
+					"in":       "path",
+					"required": true,
+					"schema":   map[string]any{"type": "string", "enum": resources},
+				},
+			},
+			"responses": map[string]any{
+				"200": map[string]any{
+					"description": "The columns, primary key, indexes and foreign keys of the resource",
+					"content": map[string]any{
+						"application/json": map[string]any{"schema": map[string]any{"type": "object"}},
+					},
+				},
+				"default": map[string]any{"$ref": "#/components/responses/Error"},
+			},
+		},
+	}
+}
+
+func resourceSchema(schema ex.Schema) map[string]any {
+
+	properties := map[string]any{}
+	for _, column := range schema.Columns {
+		property := columnSchema(column.Type)
+		if column.Nullable {
+			property["nullable"] = true
+		}
+		properties[column.Name] = property
+	}
+
+	return map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+}
+
+var integerTypes = []string{
+	"int", "integer", "tinyint", "smallint", "mediumint", "bigint",
+	"int2", "int4", "int8", "serial", "smallserial", "bigserial",
+}
+
+// columnSchema maps a database type to the closest json schema type.
+func columnSchema(dbType string) map[string]any {
+
+	t := strings.ToLower(dbType)
+
+	// The name of the type without its size or modifiers, such as unsigned
+	name := strings.TrimSpace(strings.Split(t, "(")[0])
+	if fields := strings.Fields(name); len(fields) > 0 {
+		name = fields[0]
+	}
+
+	switch {
+	case t == "tinyint(1)" || strings.HasPrefix(t, "bool"):
+		return map[string]any{"type": "boolean"}
+
+	case slices.Contains(integerTypes, name):
+		return map[string]any{"type": "integer"}
+
+	case strings.HasPrefix(t, "decimal") || strings.HasPrefix(t, "numeric") ||
+		strings.HasPrefix(t, "float") || strings.HasPrefix(t, "double") || strings.HasPrefix(t, "real"):
+		return map[string]any{"type": "number"}
+
+	case strings.HasPrefix(t, "json"):
+		return map[string]any{}
+
+	case strings.HasPrefix(t, "timestamp") || strings.HasPrefix(t, "datetime"):
+		return map[string]any{"type": "string", "format": "date-time"}
+
+	case t == "date":
+		return map[string]any{"type": "string", "format": "date"}
+
+	default:
+		return map[string]any{"type": "string"}
+	}
+}
+
+func errorSchema() map[string]any {
+
+	var codes []string
+	for code := range errorStatusCodes {
+		codes = append(codes, string(code))
+	}
+	slices.Sort(codes)
+
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"error_message": map[string]any{"type": "string"},
+			"error_type":    map[string]any{"type": "string", "enum": codes},
+			"error_code":    map[string]any{},
+		},
+	}
+}
+
+func ref(name string) map[string]any {
+	return map[string]any{"$ref": "#/components/schemas/" + name}
+}
@@ -0,0 +1,169 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/reverted/ex"
+	"github.com/reverted/ex/server"
+)
+
+var _ = Describe("OpenAPI", func() {
+
+	var (
+		response *http.Response
+		doc      map[string]any
+
+		describer    *fakeDescriber
+		interceptors []server.Interceptor
+	)
+
+	BeforeEach(func() {
+		describer = &fakeDescriber{}
+		interceptors = nil
+	})
+
+	JustBeforeEach(func() {
+		var openAPIServer *httptest.Server
+		if describer != nil {
+			openAPIServer = httptest.NewServer(server.New(newLogger(), fakeClient{},
+				server.WithTracer(noopTracer{}),
+				server.WithInterceptors(interceptors...),
+				server.WithDescriber(describer),
+				server.WithOpenAPIInfo("some-api", "1.2.3"),
+			))
+		} else {
+			openAPIServer = httptest.NewServer(server.New(newLogger(), fakeClient{},
+				server.WithTracer(noopTracer{}),
+			))
+		}
+		defer openAPIServer.Close()
+
+		var err error
+		response, err = openAPIServer.Client().Get(openAPIServer.URL + "/v1/:openapi")
+		Expect(err).NotTo(HaveOccurred())
+		defer response.Body.Close()
+
+		doc = nil
+		Expect(json.NewDecoder(response.Body).Decode(&doc)).To(Succeed())
+	})
+
+	It("responds with an openapi document", func() {
+		Expect(response.StatusCode).To(Equal(http.StatusOK))
+		Expect(doc).To(HaveKeyWithValue("openapi", "3.0.3"))
+		Expect(doc).To(HaveKeyWithValue("info", map[string]any{"title": "some-api", "version": "1.2.3"}))
+		Expect(doc).To(HaveKeyWithValue("servers", []any{map[string]any{"url": "/v1"}}))
+	})
+
+	It("has a path for each resource", func() {
+		Expect(doc["paths"]).To(HaveKey("/resources"))
+		Expect(doc["paths"]).To(HaveKey("/others"))
+		Expect(doc["paths"]).To(HaveKey("/:schema/{resource}"))
+	})
+
+	It("describes the columns of the resources", func() {
+		schemas := doc["components"].(map[string]any)["schemas"]
+		Expect(schemas).To(HaveKeyWithValue("resources", map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"id": map[string]any{"type": "integer"},
+			},
+		}))
+	})
+
+	Context("when the resources have columns of other types", func() {
+		types := map[string]string{
+			"int":               "integer",
+			"int(11) unsigned":  "integer",
+			"integer":           "integer",
+			"tinyint(4)":        "integer",
+			"smallint":          "integer",
+			"mediumint":         "integer",
+			"bigint unsigned":   "integer",
+			"int2":              "integer",
+			"int4":              "integer",
+			"int8":              "integer",
+			"serial":            "integer",
+			"smallserial":       "integer",
+			"bigserial":         "integer",
+			"tinyint(1)":        "boolean",
+			"boolean":           "boolean",
+			"decimal(10,2)":     "number",
+			"double precision":  "number",
+			"interval":          "string",
+			"point":             "string",
+			"varchar(160)":      "string",
+			"character varying": "string",
+			"timestamp":         "string",
+			"datetime":          "string",
+			"date":              "string",
+			"text":              "string",
+			"uuid":              "string",
+		}
+
+		BeforeEach(func() {
+			for t := range types {
+				describer.columns = append(describer.columns, ex.Column{Name: t, Type: t})
+			}
+		})
+
+		It("maps each type to the closest json schema type", func() {
+			schemas := doc["components"].(map[string]any)["schemas"].(map[string]any)
+			properties := schemas["resources"].(map[string]any)["properties"].(map[string]any)
+
+			for t, jsonType := range types {
+				Expect(properties[t]).To(HaveKeyWithValue("type", jsonType), t)
+			}
+		})
+	})
+
+	It("documents the filters and headers of each operation", func() {
+		get := doc["paths"].(map[string]any)["/resources"].(map[string]any)["get"].(map[string]any)
+
+		var names []string
+		for _, param := range get["parameters"].([]any) {
+			names = append(names, param.(map[string]any)["name"].(string))
+		}
+
+		Expect(names).To(ContainElements("id", ":or", "X-Columns", "X-Order-By", "X-Limit", "X-Cursor"))
+		Expect(names).NotTo(ContainElement("X-Returning"))
+
+		filter := get["parameters"].([]any)[0].(map[string]any)
+		Expect(filter).To(HaveKeyWithValue("x-operators", ContainElements("gt", "in", "not_btwn")))
+	})
+
+	Context("when an interceptor rejects a resource", func() {
+		BeforeEach(func() {
+			interceptors = []server.Interceptor{
+				server.Intercept(func(ctx context.Context, cmd ex.Command) (ex.Command, error) {
+					if cmd.Resource == "others" {
+						return cmd, errors.New("forbidden")
+					}
+					return cmd, nil
+				}),
+			}
+		})
+
+		It("leaves the resource out", func() {
+			Expect(response.StatusCode).To(Equal(http.StatusOK))
+			Expect(doc["paths"]).To(HaveKey("/resources"))
+			Expect(doc["paths"]).NotTo(HaveKey("/others"))
+		})
+	})
+
+	Context("when there is no describer", func() {
+		BeforeEach(func() {
+			describer = nil
+		})
+
+		It("responds with not implemented", func() {
+			Expect(response.StatusCode).To(Equal(http.StatusNotImplemented))
+		})
+	})
+})
@@ -2,17 +2,71 @@ package server
 
 import (
 	"bytes"
+	"encoding/csv"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
+	"mime"
 	"net/http"
 	"path"
+	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/reverted/ex"
 )
 
+var (
+	joinRegexp = regexp.MustCompile(`(?i)^(INNER|LEFT)\s+(\S+)\s+ON\s+(.+)$`)
+	andRegexp  = regexp.MustCompile(`(?i)\s+AND\s+`)
+)
+
+// header describes a header the parser reads, for the OpenAPI document.
+type header struct {
+	Name        string
+	Type        string
+	Columns     bool
+	Description string
+}
+
+// commandHeaders are the headers ParseCommand reads for each method.
+var commandHeaders = map[string][]header{
+	"GET": {
+		{"X-Columns", "string", true, "Comma separated columns to select"},
+		{"X-Join", "string", false, "Comma separated joins, as <INNER|LEFT> <resource> ON <column> = <column>"},
+		{"X-Partition-By", "string", true, "Comma separated columns to partition by"},
+		{"X-Primary-Key", "string", true, "Comma separated primary key columns"},
+		{"X-Group-By", "string", true, "Comma separated columns to group by"},
+		{"X-Order-By", "string", true, "Comma separated columns to order by, each optionally followed by ASC or DESC"},
+		{"X-Limit", "integer", false, "Maximum number of rows"},
+		{"X-Offset", "integer", false, "Number of rows to skip"},
+		{"X-Cursor", "string", false, "Cursor from X-Next-Cursor or X-Prev-Cursor, optionally prefixed with 'before '"},
+		{"X-Total", "boolean", false, "Return the number of matching rows in X-Total-Count"},
+		{"X-Read-Primary", "boolean", false, "Read from the primary instead of a replica"},
+	},
+	"POST": {
+		{"X-On-Conflict-Constraint", "string", true, "Comma separated columns of the conflicting constraint"},
+		{"X-On-Conflict-Update", "string", true, "Comma separated columns to update on conflict"},
+		{"X-On-Conflict-Ignore", "string", false, "true, or a column, to ignore conflicts"},
+		{"X-On-Conflict-Error", "string", false, "true to error on conflicts"},
+		{"X-Primary-Key", "string", true, "Comma separated primary key columns"},
+		{"X-Returning", "string", true, "Comma separated columns to return"},
+	},
+	"PUT": {
+		{"X-Order-By", "string", true, "Comma separated columns to order by, each optionally followed by ASC or DESC"},
+		{"X-Limit", "integer", false, "Maximum number of rows"},
+		{"X-Returning", "string", true, "Comma separated columns to return"},
+	},
+	"DELETE": {
+		{"X-Order-By", "string", true, "Comma separated columns to order by, each optionally followed by ASC or DESC"},
+		{"X-Limit", "integer", false, "Maximum number of rows"},
+		{"X-Returning", "string", true, "Comma separated columns to return"},
+	},
+}
+
 func NewParser() *parser {
 	return &parser{}
 }
@@ -31,6 +85,9 @@ func (p *parser) Parse(r *http.Request) (ex.Request, error) {
 		return p.ParseBatch(r)
 
 	default:
+		if r.Method == "POST" && p.isLoad(r) {
+			return p.ParseLoad(r)
+		}
 		return p.ParseCommand(r)
 	}
 }
@@ -61,11 +118,16 @@ func (p *parser) ParseBatch(r *http.Request) (ex.Request, error) {
 
 	var cmds struct {
 		Commands []ex.Command `json:"requests,omitempty"`
+		Partial  bool         `json:"partial,omitempty"`
+		Results  bool         `json:"results,omitempty"`
 	}
 	if err = json.Unmarshal(body, &cmds); err != nil {
 		return batch, err
 	}
 
+	batch.Partial = cmds.Partial
+	batch.Results = cmds.Results
+
 	for _, c := range cmds.Commands {
 		batch.Requests = append(batch.Requests, c)
 	}
@@ -82,6 +144,11 @@ func (p *parser) ParseCommand(r *http.Request) (ex.Request, error) {
 		return ex.Command{}, err
 	}
 
+	having, err := p.ParseHaving(r)
+	if err != nil {
+		return ex.Command{}, err
+	}
+
 	values, err := p.ParseValues(r)
 	if err != nil {
 		return ex.Command{}, err
@@ -92,6 +159,11 @@ func (p *parser) ParseCommand(r *http.Request) (ex.Request, error) {
 		return ex.Command{}, err
 	}
 
+	joins, err := p.ParseJoin(r)
+	if err != nil {
+		return ex.Command{}, err
+	}
+
 	groupBy, err := p.ParseGroupBy(r)
 	if err != nil {
 		return ex.Command{}, err
@@ -112,6 +184,21 @@ func (p *parser) ParseCommand(r *http.Request) (ex.Request, error) {
 		return ex.Command{}, err
 	}
 
+	cursor, err := p.ParseCursor(r)
+	if err != nil {
+		return ex.Command{}, err
+	}
+
+	total, err := p.ParseTotal(r)
+	if err != nil {
+		return ex.Command{}, err
+	}
+
+	readPrimary, err := p.ParseReadPrimary(r)
+	if err != nil {
+		return ex.Command{}, err
+	}
+
 	partition, err := p.ParsePartition(r)
 	if err != nil {
 		return ex.Command{}, err
@@ -122,41 +209,53 @@ func (p *parser) ParseCommand(r *http.Request) (ex.Request, error) {
 		return ex.Command{}, err
 	}
 
+	returning, err := p.ParseReturning(r)
+	if err != nil {
+		return ex.Command{}, err
+	}
+
+	primaryKey, err := p.ParsePrimaryKey(r)
+	if err != nil {
+		return ex.Command{}, err
+	}
+
 	switch r.Method {
 	case "GET":
 		return ex.Query(
 			resource,
 			where,
 			ex.Columns(columns...),
+			joins,
 			ex.PartitionBy(partition...),
+			primaryKey,
 			ex.GroupBy(groupBy...),
+			having,
 			ex.OrderBy(order...),
 			ex.Limit(limit),
 			ex.Offset(offset),
+			cursor,
+			ex.TotalConfig(total),
+			ex.ReadPrimaryConfig(readPrimary),
 		), nil
 
 	case "DELETE":
-		return ex.Delete(resource, where, ex.OrderBy(order...), ex.Limit(limit)), nil
+		return ex.Delete(resource, where, ex.OrderBy(order...), ex.Limit(limit), returning), nil
 
 	case "POST":
 		if len(values) == 0 {
 			return ex.Command{}, errors.New("body does not contain a valid object or array")
 		}
 		if len(values) == 1 {
-			return ex.Insert(resource, values[0], conflict), nil
-		}
-		var cmds []ex.Request
-		for _, v := range values {
-			cmds = append(cmds, ex.Insert(resource, v, conflict))
+			return ex.Insert(resource, values[0], conflict, primaryKey, returning), nil
 		}
-		return ex.Bulk(cmds...), nil
+		return ex.Insert(resource, ex.Rows(values...), conflict, primaryKey, returning), nil
 
 	case "PUT":
 		if len(values) == 0 {
 			return ex.Command{}, errors.New("body does not contain a valid object or array")
 		}
 		if len(values) == 1 {
-			return ex.Update(resource, values[0], where, ex.OrderBy(order...), ex.Limit(limit)), nil
+			return ex.Update(resource, values[0], where, ex.OrderBy(order...), ex.Limit(limit), returning), nil
 		}
 		return ex.Command{}, errors.New("arrays not supported in PUT body")
 
@@ -164,6 +263,145 @@ func (p *parser) ParseCommand(r *http.Request) (ex.Request, error) {
 	return ex.Command{}, errors.New("unsupported method '" + r.Method + "'")
 }
 
+func (p *parser) isLoad(r *http.Request) bool {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	switch mediaType {
+	case "application/x-ndjson", "text/csv":
+		return true
+	default:
+		return false
+	}
+}
+
+// ParseLoad streams an NDJSON or CSV body into a bulk load. CSV columns are
+// read from the header row and empty fields load as NULL. NDJSON lines are
+// either objects or arrays ordered by X-Columns; without X-Columns the keys
+// of the first object are used.
+func (p *parser) ParseLoad(r *http.Request) (ex.Request, error) {
+
+	resource := p.ParseResource(r)
+
+	columns, err := p.ParseColumns(r)
+	if err != nil {
+		return ex.Load{}, err
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	if mediaType == "text/csv" {
+		reader := csv.NewReader(r.Body)
+
+		header, err := reader.Read()
+		if err != nil {
+			return ex.Load{}, err
+		}
+
+		return ex.BulkLoad(resource, header, &csvIterator{reader}), nil
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.UseNumber()
+
+	rows := &ndjsonIterator{decoder: decoder, columns: columns}
+
+	if len(columns) == 0 {
+		var first map[string]any
+		if err := decoder.Decode(&first); err != nil {
+			return ex.Load{}, errors.New("body does not start with an object and X-Columns is missing")
+		}
+
+		for key := range first {
+			rows.columns = append(rows.columns, key)
+		}
+		sort.Strings(rows.columns)
+
+		rows.first = first
+	}
+
+	return ex.BulkLoad(resource, rows.columns, rows), nil
+}
+
+type csvIterator struct {
+	reader *csv.Reader
+}
+
+func (i *csvIterator) Next() ([]any, error) {
+
+	record, err := i.reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	row := make([]any, len(record))
+	for j, field := range record {
+		if field != "" {
+			row[j] = field
+		}
+	}
+
+	return row, nil
+}
+
+type ndjsonIterator struct {
+	decoder *json.Decoder
+	columns []string
+	first   map[string]any
+}
+
+func (i *ndjsonIterator) Next() ([]any, error) {
+
+	if i.first != nil {
+		first := i.first
+		i.first = nil
+		return i.fromObject(first)
+	}
+
+	var line json.RawMessage
+	if err := i.decoder.Decode(&line); err != nil {
+		return nil, err
+	}
+
+	if bytes.HasPrefix(bytes.TrimSpace(line), []byte("[")) {
+		var row []any
+		if err := i.unmarshal(line, &row); err != nil {
+			return nil, err
+		}
+		if len(row) != len(i.columns) {
+			return nil, fmt.Errorf("expected %d values, got %d", len(i.columns), len(row))
+		}
+		return row, nil
+	}
+
+	var object map[string]any
+	if err := i.unmarshal(line, &object); err != nil {
+		return nil, err
+	}
+
+	return i.fromObject(object)
+}
+
+func (i *ndjsonIterator) fromObject(object map[string]any) ([]any, error) {
+	row := make([]any, len(i.columns))
+	for j, column := range i.columns {
+		row[j] = object[column]
+	}
+
+	for key := range object {
+		if !slices.Contains(i.columns, key) {
+			return nil, fmt.Errorf("unexpected column: %s", key)
+		}
+	}
+
+	return row, nil
+}
+
+func (i *ndjsonIterator) unmarshal(data []byte, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
 func (p *parser) ParseValues(r *http.Request) ([]ex.Values, error) {
 	defer r.Body.Close()
 
@@ -199,6 +437,30 @@ func (p *parser) ParseColumns(r *http.Request) ([]string, error) {
 	}
 }
 
+func (p *parser) ParseJoin(r *http.Request) (ex.JoinConfig, error) {
+	var joins ex.JoinConfig
+
+	param := r.Header.Get("X-Join")
+	if len(param) == 0 {
+		return joins, nil
+	}
+
+	for _, clause := range strings.Split(param, ",") {
+		matches := joinRegexp.FindStringSubmatch(strings.TrimSpace(clause))
+		if len(matches) == 0 {
+			return nil, errors.New("unsupported 'X-Join' header: " + clause)
+		}
+
+		joins = append(joins, ex.JoinClause{
+			Type:     strings.ToUpper(matches[1]),
+			Resource: matches[2],
+			On:       andRegexp.Split(matches[3], -1),
+		})
+	}
+
+	return joins, nil
+}
+
 func (p *parser) ParseGroupBy(r *http.Request) ([]string, error) {
 	if param := r.Header.Get("X-Group-By"); len(param) > 0 {
 		return strings.Split(param, ","), nil
@@ -233,6 +495,35 @@ func (p *parser) ParseOffset(r *http.Request) (int, error) {
 	}
 }
 
+func (p *parser) ParseCursor(r *http.Request) (ex.CursorConfig, error) {
+	param := r.Header.Get("X-Cursor")
+	if len(param) == 0 {
+		return ex.CursorConfig{}, nil
+	}
+
+	if token, ok := strings.CutPrefix(param, "before "); ok {
+		return ex.CursorConfig{Before: strings.TrimSpace(token)}, nil
+	}
+
+	return ex.CursorConfig{After: strings.TrimPrefix(param, "after ")}, nil
+}
+
+func (p *parser) ParseTotal(r *http.Request) (bool, error) {
+	if param := r.Header.Get("X-Total"); len(param) > 0 {
+		return strconv.ParseBool(param)
+	} else {
+		return false, nil
+	}
+}
+
+func (p *parser) ParseReadPrimary(r *http.Request) (bool, error) {
+	if param := r.Header.Get("X-Read-Primary"); len(param) > 0 {
+		return strconv.ParseBool(param)
+	} else {
+		return false, nil
+	}
+}
+
 func (p *parser) ParsePartition(r *http.Request) ([]string, error) {
 	if param := r.Header.Get("X-Partition-By"); len(param) > 0 {
 		return strings.Split(param, ","), nil
@@ -241,6 +532,22 @@ func (p *parser) ParsePartition(r *http.Request) ([]string, error) {
 	}
 }
 
+func (p *parser) ParseReturning(r *http.Request) (ex.ReturningConfig, error) {
+	if param := r.Header.Get("X-Returning"); len(param) > 0 {
+		return strings.Split(param, ","), nil
+	} else {
+		return nil, nil
+	}
+}
+
+func (p *parser) ParsePrimaryKey(r *http.Request) (ex.PrimaryKeyConfig, error) {
+	if param := r.Header.Get("X-Primary-Key"); len(param) > 0 {
+		return strings.Split(param, ","), nil
+	} else {
+		return nil, nil
+	}
+}
+
 func (p *parser) ParseConflict(r *http.Request) (ex.OnConflictConfig, error) {
 	conflict := ex.OnConflictConfig{}
 
@@ -265,15 +572,48 @@ func (p *parser) ParseConflict(r *http.Request) (ex.OnConflictConfig, error) {
 
 func (p *parser) ParseWhere(r *http.Request) (ex.Where, error) {
 
+	return p.parseConditions(r, func(k string) (string, bool) {
+		return k, !strings.HasPrefix(k, "having.")
+	})
+}
+
+func (p *parser) ParseHaving(r *http.Request) (ex.Having, error) {
+
+	having, err := p.parseConditions(r, func(k string) (string, bool) {
+		return strings.CutPrefix(k, "having.")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ex.Having(having), nil
+}
+
+func (p *parser) parseConditions(r *http.Request, match func(string) (string, bool)) (ex.Where, error) {
+
 	where := ex.Where{}
+	query := r.URL.Query()
 
-	for k, v := range r.URL.Query() {
-		key, value, err := ex.ParseWhereArg(k, v[0])
-		if err != nil {
-			return nil, err
+	var keys []string
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		name, ok := match(k)
+		if !ok {
+			continue
 		}
 
-		where[key] = value
+		for _, v := range query[k] {
+			key, value, err := ex.ParseWhereArg(name, v)
+			if err != nil {
+				return nil, err
+			}
+
+			where.Add(key, value)
+		}
 	}
 
 	return where, nil
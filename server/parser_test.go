@@ -2,6 +2,7 @@ package server_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"io"
 	"net/http"
 	"net/url"
@@ -62,6 +63,44 @@ var _ = Describe("Parser", func() {
 			})
 		})
 
+		Context("when the request has multiple predicates on a column", func() {
+			BeforeEach(func() {
+				req.URL.RawQuery = "id:gt=1&id:not_eq=5&name:not_like=a%25&name:not_like=b%25"
+			})
+
+			It("parses the request", func() {
+				Expect(res).To(Equal(ex.Query("resources", ex.Where{
+					"id":   ex.All(ex.Gt("1"), ex.NotEq("5")),
+					"name": ex.All(ex.NotLike("a%"), ex.NotLike("b%")),
+				})))
+			})
+		})
+
+		Context("when the request has condition groups", func() {
+			BeforeEach(func() {
+				values := url.Values{}
+				values.Add(":or", `[{"status":"active"},{":not":{"owner_id:gt":"5"}}]`)
+				req.URL.RawQuery = values.Encode()
+			})
+
+			It("parses the request", func() {
+				Expect(res).To(Equal(ex.Query("resources", ex.Or(
+					ex.Where{"status": "active"},
+					ex.Not(ex.Where{"owner_id": ex.Gt("5")}),
+				))))
+			})
+		})
+
+		Context("when the request has malformed condition groups", func() {
+			BeforeEach(func() {
+				req.URL.RawQuery = url.Values{":or": []string{"not-json"}}.Encode()
+			})
+
+			It("errors", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
 		Context("when the request has columns", func() {
 			BeforeEach(func() {
 				req.Header.Add("X-Columns", "name")
@@ -72,6 +111,44 @@ var _ = Describe("Parser", func() {
 			})
 		})
 
+		Context("when the request has having", func() {
+			BeforeEach(func() {
+				req.URL.RawQuery = "key=value&having.count(id):gt=10"
+				req.Header.Add("X-Group-By", "key")
+			})
+
+			It("parses the request", func() {
+				Expect(res).To(Equal(ex.Query("resources",
+					ex.Where{"key": "value"},
+					ex.GroupBy("key"),
+					ex.Having{"count(id)": ex.Gt("10")},
+				)))
+			})
+		})
+
+		Context("when the request has joins", func() {
+			BeforeEach(func() {
+				req.Header.Add("X-Join", "INNER owners ON owners.id = resources.owner_id,left tags ON tags.resource_id = resources.id and tags.owner_id = owners.id")
+			})
+
+			It("parses the request", func() {
+				Expect(res).To(Equal(ex.Query("resources",
+					ex.Join("owners", "owners.id = resources.owner_id"),
+					ex.LeftJoin("tags", "tags.resource_id = resources.id", "tags.owner_id = owners.id"),
+				)))
+			})
+		})
+
+		Context("when the request has an invalid join", func() {
+			BeforeEach(func() {
+				req.Header.Add("X-Join", "CROSS owners")
+			})
+
+			It("errors", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
 		Context("when the request has order", func() {
 			BeforeEach(func() {
 				req.Header.Add("X-Group-By", "name")
@@ -132,6 +209,56 @@ var _ = Describe("Parser", func() {
 			})
 		})
 
+		Context("when the request has a cursor", func() {
+			BeforeEach(func() {
+				req.Header.Add("X-Cursor", "some-cursor")
+			})
+
+			It("parses the request", func() {
+				Expect(res).To(Equal(ex.Query("resources", ex.After("some-cursor"))))
+			})
+		})
+
+		Context("when the request has a before cursor", func() {
+			BeforeEach(func() {
+				req.Header.Add("X-Cursor", "before some-cursor")
+			})
+
+			It("parses the request", func() {
+				Expect(res).To(Equal(ex.Query("resources", ex.Before("some-cursor"))))
+			})
+		})
+
+		Context("when the request has total", func() {
+			BeforeEach(func() {
+				req.Header.Add("X-Total", "true")
+			})
+
+			It("parses the request", func() {
+				Expect(res).To(Equal(ex.Query("resources", ex.WithTotal())))
+			})
+		})
+
+		Context("when the request has an invalid total", func() {
+			BeforeEach(func() {
+				req.Header.Add("X-Total", "value")
+			})
+
+			It("errors", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		Context("when the request reads from the primary", func() {
+			BeforeEach(func() {
+				req.Header.Add("X-Read-Primary", "true")
+			})
+
+			It("parses the request", func() {
+				Expect(res).To(Equal(ex.Query("resources", ex.ReadPrimary())))
+			})
+		})
+
 		Context("when the request has partition by", func() {
 			BeforeEach(func() {
 				req.Header.Add("X-Partition-By", "user_id")
@@ -151,6 +278,17 @@ var _ = Describe("Parser", func() {
 				Expect(res).To(Equal(ex.Query("resources", ex.PartitionBy("user_id", "category"))))
 			})
 		})
+
+		Context("when the request has a primary key", func() {
+			BeforeEach(func() {
+				req.Header.Add("X-Partition-By", "user_id")
+				req.Header.Add("X-Primary-Key", "tenant_id,uuid")
+			})
+
+			It("parses the request", func() {
+				Expect(res).To(Equal(ex.Query("resources", ex.PartitionBy("user_id"), ex.PrimaryKey("tenant_id", "uuid"))))
+			})
+		})
 	})
 
 	Describe("DELETE", func() {
@@ -210,6 +348,17 @@ var _ = Describe("Parser", func() {
 			})
 		})
 
+		Context("when the request has an array body", func() {
+			BeforeEach(func() {
+				req.Body = io.NopCloser(bytes.NewBufferString(`[{"key": "value1"}, {"key": "value2"}]`))
+				req.Header.Add("X-On-Conflict-Update", "key")
+			})
+
+			It("parses the request into a single multi-row insert", func() {
+				Expect(res).To(Equal(ex.Insert("resources", ex.Rows(ex.Values{"key": "value1"}, ex.Values{"key": "value2"}), ex.OnConflictUpdate("key"))))
+			})
+		})
+
 		Context("when the request has body", func() {
 			BeforeEach(func() {
 				req.Body = io.NopCloser(bytes.NewBufferString(`{"key": "value"}`))
@@ -261,6 +410,80 @@ var _ = Describe("Parser", func() {
 		})
 	})
 
+	Describe("LOAD", func() {
+		var rows [][]any
+
+		BeforeEach(func() {
+			req.Method = "POST"
+			rows = nil
+		})
+
+		JustBeforeEach(func() {
+			if load, ok := res.(ex.Load); ok && err == nil {
+				for {
+					row, err := load.Rows.Next()
+					if err != nil {
+						Expect(err).To(Equal(io.EOF))
+						break
+					}
+					rows = append(rows, row)
+				}
+			}
+		})
+
+		Context("when the body is ndjson objects", func() {
+			BeforeEach(func() {
+				req.Header.Set("Content-Type", "application/x-ndjson")
+				req.Body = io.NopCloser(bytes.NewBufferString("{\"name\": \"a\", \"id\": 1}\n{\"id\": 2}\n"))
+			})
+
+			It("parses the columns from the first object", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(res.(ex.Load).Resource).To(Equal("resources"))
+				Expect(res.(ex.Load).Columns).To(Equal([]string{"id", "name"}))
+				Expect(rows).To(Equal([][]any{{json.Number("1"), "a"}, {json.Number("2"), nil}}))
+			})
+		})
+
+		Context("when the body is ndjson arrays", func() {
+			BeforeEach(func() {
+				req.Header.Set("Content-Type", "application/x-ndjson")
+				req.Header.Set("X-Columns", "id,name")
+				req.Body = io.NopCloser(bytes.NewBufferString("[1, \"a\"]\n[2, null]\n"))
+			})
+
+			It("parses the columns from the header", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(res.(ex.Load).Columns).To(Equal([]string{"id", "name"}))
+				Expect(rows).To(Equal([][]any{{json.Number("1"), "a"}, {json.Number("2"), nil}}))
+			})
+		})
+
+		Context("when the body is ndjson arrays without columns", func() {
+			BeforeEach(func() {
+				req.Header.Set("Content-Type", "application/x-ndjson")
+				req.Body = io.NopCloser(bytes.NewBufferString("[1, \"a\"]\n"))
+			})
+
+			It("errors", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		Context("when the body is csv", func() {
+			BeforeEach(func() {
+				req.Header.Set("Content-Type", "text/csv; charset=utf-8")
+				req.Body = io.NopCloser(bytes.NewBufferString("id,name\n1,a\n2,\n"))
+			})
+
+			It("parses the columns from the header row", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(res.(ex.Load).Columns).To(Equal([]string{"id", "name"}))
+				Expect(rows).To(Equal([][]any{{"1", "a"}, {"2", nil}}))
+			})
+		})
+	})
+
 	Describe("PUT", func() {
 		BeforeEach(func() {
 			req.Method = "PUT"
@@ -323,6 +546,38 @@ var _ = Describe("Parser", func() {
 		})
 	})
 
+	Describe("BATCH", func() {
+		BeforeEach(func() {
+			req.Method = "POST"
+			req.URL.Path = "/v1/:batch"
+			req.Body = io.NopCloser(bytes.NewBufferString(`{"requests": [{"action": "DELETE", "resource": "resources"}]}`))
+		})
+
+		It("parses the request", func() {
+			Expect(res).To(Equal(ex.Bulk(ex.Command{Action: "DELETE", Resource: "resources"})))
+		})
+
+		Context("when the batch is partial", func() {
+			BeforeEach(func() {
+				req.Body = io.NopCloser(bytes.NewBufferString(`{"requests": [{"action": "DELETE", "resource": "resources"}], "partial": true}`))
+			})
+
+			It("parses the request", func() {
+				Expect(res).To(Equal(ex.Partial(ex.Command{Action: "DELETE", Resource: "resources"})))
+			})
+		})
+
+		Context("when the batch returns each result", func() {
+			BeforeEach(func() {
+				req.Body = io.NopCloser(bytes.NewBufferString(`{"requests": [{"action": "DELETE", "resource": "resources"}], "results": true}`))
+			})
+
+			It("parses the request", func() {
+				Expect(res).To(Equal(ex.Batch{Requests: []ex.Request{ex.Command{Action: "DELETE", Resource: "resources"}}, Results: true}))
+			})
+		})
+	})
+
 	Describe("Modifiers", func() {
 		BeforeEach(func() {
 			req.Method = "GET"
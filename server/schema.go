@@ -0,0 +1,71 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path"
+
+	"github.com/reverted/ex"
+)
+
+type Describer interface {
+	Describe(context.Context, string) (ex.Schema, error)
+}
+
+// WithDescriber enables the GET /:schema/{resource} endpoint, which responds
+// with the columns, keys and indexes of the resource.
+func WithDescriber(describer Describer) opt {
+	return func(s *server) {
+		s.Describer = describer
+	}
+}
+
+func (s *server) serveSchema(w http.ResponseWriter, r *http.Request) {
+
+	schema, err := s.describe(r)
+	if err != nil {
+		s.Logger.Error(err)
+		s.writeError(w, r, err)
+		return
+	}
+
+	s.Logger.Infof("<<< %v : %v [200]", r.Method, r.URL)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(schema)
+}
+
+func (s *server) describe(r *http.Request) (ex.Schema, error) {
+
+	if r.Method != "GET" {
+		return ex.Schema{}, NewStatusError(http.StatusMethodNotAllowed, errors.New("unsupported method '"+r.Method+"'"))
+	}
+
+	if s.Describer == nil {
+		return ex.Schema{}, NewStatusError(http.StatusNotImplemented, errors.New("schemas are not supported"))
+	}
+
+	resource, err := s.interceptResource(r.Context(), path.Base(r.URL.Path))
+	if err != nil {
+		return ex.Schema{}, err
+	}
+
+	return s.Describer.Describe(r.Context(), resource)
+}
+
+// interceptResource lets interceptors reject a resource's schema like they
+// would its rows.
+func (s *server) interceptResource(ctx context.Context, resource string) (string, error) {
+
+	cmd := ex.Query(resource)
+	for _, i := range s.Interceptors {
+		var err error
+		if cmd, err = i.Intercept(ctx, cmd); err != nil {
+			return "", err
+		}
+	}
+
+	return cmd.Resource, nil
+}
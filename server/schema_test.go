@@ -0,0 +1,132 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/reverted/ex"
+	"github.com/reverted/ex/server"
+)
+
+var _ = Describe("Schema", func() {
+
+	var (
+		response *http.Response
+		body     map[string]any
+
+		describer    *fakeDescriber
+		interceptors []server.Interceptor
+		path         string
+	)
+
+	BeforeEach(func() {
+		describer = &fakeDescriber{}
+		interceptors = nil
+		path = "/v1/:schema/resources"
+	})
+
+	JustBeforeEach(func() {
+		var schemaServer *httptest.Server
+		if describer != nil {
+			schemaServer = httptest.NewServer(server.New(newLogger(), fakeClient{},
+				server.WithTracer(noopTracer{}),
+				server.WithInterceptors(interceptors...),
+				server.WithDescriber(describer),
+			))
+		} else {
+			schemaServer = httptest.NewServer(server.New(newLogger(), fakeClient{},
+				server.WithTracer(noopTracer{}),
+			))
+		}
+		defer schemaServer.Close()
+
+		var err error
+		response, err = schemaServer.Client().Get(schemaServer.URL + path)
+		Expect(err).NotTo(HaveOccurred())
+		defer response.Body.Close()
+
+		body = nil
+		Expect(json.NewDecoder(response.Body).Decode(&body)).To(Succeed())
+	})
+
+	It("responds with the schema of the resource", func() {
+		Expect(response.StatusCode).To(Equal(http.StatusOK))
+		Expect(describer.resource).To(Equal("resources"))
+		Expect(body).To(HaveKeyWithValue("resource", "resources"))
+		Expect(body).To(HaveKeyWithValue("primary_key", []any{"id"}))
+		Expect(body).To(HaveKeyWithValue("columns", []any{
+			map[string]any{"name": "id", "type": "int", "nullable": false},
+		}))
+	})
+
+	Context("when the resource is unknown", func() {
+		BeforeEach(func() {
+			path = "/v1/:schema/unknown"
+		})
+
+		It("responds with not found", func() {
+			Expect(response.StatusCode).To(Equal(http.StatusNotFound))
+			Expect(body).To(HaveKeyWithValue("error_type", "not_found"))
+		})
+	})
+
+	Context("when an interceptor rejects the resource", func() {
+		BeforeEach(func() {
+			interceptors = []server.Interceptor{
+				server.Intercept(func(ctx context.Context, cmd ex.Command) (ex.Command, error) {
+					return cmd, errors.New("forbidden")
+				}),
+			}
+		})
+
+		It("doesn't describe the resource", func() {
+			Expect(response.StatusCode).To(Equal(http.StatusBadRequest))
+			Expect(describer.resource).To(BeEmpty())
+		})
+	})
+
+	Context("when there is no describer", func() {
+		BeforeEach(func() {
+			describer = nil
+		})
+
+		It("responds with not implemented", func() {
+			Expect(response.StatusCode).To(Equal(http.StatusNotImplemented))
+		})
+	})
+})
+
+type fakeDescriber struct {
+	resource string
+	columns  []ex.Column
+}
+
+func (d *fakeDescriber) Describe(ctx context.Context, resource string) (ex.Schema, error) {
+	d.resource = resource
+
+	if resource != "resources" && resource != "others" {
+		return ex.Schema{}, ex.NewError(ex.NotFound, fmt.Errorf("unknown resource: %s", resource))
+	}
+
+	columns := d.columns
+	if columns == nil {
+		columns = []ex.Column{{Name: "id", Type: "int"}}
+	}
+
+	return ex.Schema{
+		Resource:   resource,
+		Columns:    columns,
+		PrimaryKey: []string{"id"},
+	}, nil
+}
+
+func (d *fakeDescriber) Resources(ctx context.Context) ([]string, error) {
+	return []string{"resources", "others"}, nil
+}
@@ -5,10 +5,15 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"path"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
 	"github.com/reverted/ex"
 )
 
@@ -42,6 +47,17 @@ type Processor interface {
 	Process(context.Context, []map[string]any) ([]map[string]any, error)
 }
 
+// Encoder writes response rows in the format of its content type.
+type Encoder interface {
+	ContentType() string
+	NewRowEncoder(io.Writer) RowEncoder
+}
+
+type RowEncoder interface {
+	Encode(map[string]any) error
+	Close() error
+}
+
 type opt func(*server)
 
 func WithParser(parser Parser) opt {
@@ -68,6 +84,15 @@ func WithProcessors(processors ...Processor) opt {
 	}
 }
 
+// WithEncoders adds or replaces the encoders for their content types.
+func WithEncoders(encoders ...Encoder) opt {
+	return func(s *server) {
+		for _, encoder := range encoders {
+			s.Encoders[encoder.ContentType()] = encoder
+		}
+	}
+}
+
 func WithContextKeys(keys ...string) opt {
 	return func(s *server) {
 		for _, key := range keys {
@@ -85,8 +110,17 @@ func New(logger Logger, client Client, opts ...opt) *server {
 		Interceptors: []Interceptor{},
 		Processors:   []Processor{},
 		IncludeKeys:  map[string]bool{},
+		Encoders:     map[string]Encoder{},
+
+		OpenAPITitle:   "ex",
+		OpenAPIVersion: "1.0.0",
+
+		TxIdleTimeout: time.Minute,
+		Transactions:  &transactions{txs: map[string]*transaction{}},
 	}
 
+	WithEncoders(NewJsonEncoder(), NewNdjsonEncoder(), NewCsvEncoder())(server)
+
 	for _, opt := range opts {
 		opt(server)
 	}
@@ -102,6 +136,15 @@ type server struct {
 	Interceptors []Interceptor
 	Processors   []Processor
 	IncludeKeys  map[string]bool
+	Encoders     map[string]Encoder
+
+	Describer      Describer
+	OpenAPITitle   string
+	OpenAPIVersion string
+
+	Transactor    Transactor
+	TxIdleTimeout time.Duration
+	Transactions  *transactions
 }
 
 func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -114,48 +157,226 @@ func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	ctx = context.WithValue(ctx, ctxKeyMethod, r.Method)
 	ctx = context.WithValue(ctx, ctxKeyResource, path.Base(r.URL.Path))
 
-	if data, err := s.serve(r.WithContext(ctx)); err != nil {
+	meta := &ex.Meta{}
+	ctx = ex.WithMeta(ctx, meta)
+
+	switch path.Base(r.URL.Path) {
+	case ":begin", ":commit", ":rollback":
+		s.serveTx(w, r.WithContext(ctx))
+		return
+
+	case ":openapi":
+		s.serveOpenAPI(w, r.WithContext(ctx))
+		return
+	}
+
+	if path.Base(path.Dir(r.URL.Path)) == ":schema" {
+		s.serveSchema(w, r.WithContext(ctx))
+		return
+	}
+
+	encoder, err := s.negotiate(r)
+	if err != nil {
 		s.Logger.Error(err)
+		s.writeError(w, r, err)
+		return
+	}
 
-		statusCode := s.statusCode(err)
-		statusMessage := s.errorMessage(err)
+	rows := &rowWriter{w: w, encoder: encoder}
 
-		s.Logger.Infof("<<< %v : %v [%v] %v", r.Method, r.URL, statusCode, statusMessage)
+	if data, err := s.serve(r.WithContext(ctx), rows); err != nil {
+		s.Logger.Error(err)
+
+		if rows.started {
+			// The status was sent with the first row, so the connection is
+			// aborted for the client to see the response is incomplete
+			s.Logger.Infof("<<< %v : %v [200] aborted", r.Method, r.URL)
+			panic(http.ErrAbortHandler)
+		}
 
-		w.WriteHeader(statusCode)
-		json.NewEncoder(w).Encode(statusMessage)
+		s.writeError(w, r, err)
+
+	} else if rows.started {
+		s.Logger.Infof("<<< %v : %v [200]", r.Method, r.URL)
 
 	} else {
 		s.Logger.Infof("<<< %v : %v [200]", r.Method, r.URL)
 
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(data)
+		if meta.NextCursor != "" {
+			w.Header().Set("X-Next-Cursor", meta.NextCursor)
+		}
+
+		if meta.PrevCursor != "" {
+			w.Header().Set("X-Prev-Cursor", meta.PrevCursor)
+		}
+
+		if meta.Total != nil {
+			w.Header().Set("X-Total-Count", strconv.FormatInt(*meta.Total, 10))
+		}
+
+		if meta.RowsAffected != nil {
+			w.Header().Set("X-Rows-Affected", strconv.FormatInt(*meta.RowsAffected, 10))
+		}
+
+		for _, row := range data {
+			if err := rows.WriteRow(row); err != nil {
+				s.Logger.Error(err)
+				return
+			}
+		}
+
+		if err := rows.Close(); err != nil {
+			s.Logger.Error(err)
+		}
 	}
 }
 
-func (s *server) serve(r *http.Request) ([]map[string]any, error) {
+func (s *server) writeError(w http.ResponseWriter, r *http.Request, err error) {
+
+	statusCode := s.statusCode(err)
+	statusMessage := s.errorMessage(err)
+
+	s.Logger.Infof("<<< %v : %v [%v] %v", r.Method, r.URL, statusCode, statusMessage)
+
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(statusMessage)
+}
+
+func (s *server) serve(r *http.Request, rows *rowWriter) ([]map[string]any, error) {
 
 	req, err := s.Parser.Parse(r)
 	if err != nil {
 		return nil, err
 	}
 
+	client := s.Client
+
+	if id := r.Header.Get("X-Transaction"); id != "" {
+		tx, err := s.acquire(id)
+		if err != nil {
+			return nil, err
+		}
+
+		defer s.release(tx)
+		client = tx
+	}
+
 	switch c := req.(type) {
 	case ex.Statement:
-		return s.batch(r.Context(), ex.Bulk(c))
+		return s.batch(r.Context(), client, ex.Bulk(c))
 
 	case ex.Command:
-		return s.batch(r.Context(), ex.Bulk(c))
+		if s.streams(c) {
+			return nil, s.stream(r.Context(), client, c, rows)
+		}
+		return s.batch(r.Context(), client, ex.Bulk(c))
 
 	case ex.Batch:
-		return s.batch(r.Context(), c)
+		return s.batch(r.Context(), client, c)
+
+	case ex.Load:
+		return s.batch(r.Context(), client, ex.Bulk(c))
 
 	default:
 		return nil, errors.New("not supported")
 	}
 }
 
-func (s *server) batch(ctx context.Context, batch ex.Batch) ([]map[string]any, error) {
+// streams reports whether a query can be written as its rows are read.
+// Processors need the whole result and paged queries are small enough to
+// buffer, which leaves their headers free for the page metadata.
+func (s *server) streams(cmd ex.Command) bool {
+	return strings.ToUpper(cmd.Action) == "QUERY" &&
+		len(s.Processors) == 0 &&
+		cmd.LimitConfig == 0 &&
+		!bool(cmd.TotalConfig)
+}
+
+func (s *server) stream(ctx context.Context, client Client, cmd ex.Command, rows *rowWriter) error {
+
+	reqs, err := s.requests(ctx, ex.Bulk(cmd))
+	if err != nil {
+		return err
+	}
+
+	if err := client.ExecContext(ctx, ex.Bulk(reqs...), ex.StreamFunc(rows.WriteRow)); err != nil {
+		return err
+	}
+
+	return rows.Close()
+}
+
+func (s *server) batch(ctx context.Context, client Client, batch ex.Batch) ([]map[string]any, error) {
+
+	reqs, err := s.requests(ctx, batch)
+	if err != nil {
+		return nil, err
+	}
+
+	if batch.Results && !batch.Partial {
+		return s.results(ctx, client, batch, reqs)
+	}
+
+	var data []map[string]any
+	if err = client.ExecContext(ctx, ex.Batch{Requests: reqs, Partial: batch.Partial}, &data); err != nil {
+		return nil, err
+	}
+
+	// A partial batch responds with the status of each request rather than
+	// rows to process
+	if batch.Partial {
+		return data, nil
+	}
+
+	for _, p := range s.Processors {
+		data, err = p.Process(ctx, data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return data, nil
+}
+
+// results responds with the rows of each request of the batch, as
+// [{"data": [...]}, ...].
+func (s *server) results(ctx context.Context, client Client, batch ex.Batch, reqs []ex.Request) ([]map[string]any, error) {
+
+	rows := make([][]map[string]any, len(batch.Requests))
+
+	results := ex.Results{}
+	for i := range rows {
+		results = append(results, &rows[i])
+	}
+
+	if err := client.ExecContext(ctx, ex.Batch{Requests: reqs, Results: true}, results); err != nil {
+		return nil, err
+	}
+
+	var err error
+	var data []map[string]any
+
+	for _, r := range rows {
+		for _, p := range s.Processors {
+			r, err = p.Process(ctx, r)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if r == nil {
+			r = []map[string]any{}
+		}
+
+		data = append(data, map[string]any{"data": r})
+	}
+
+	return data, nil
+}
+
+// requests intercepts the commands of the batch and wraps it with the
+// session variables for the included context keys.
+func (s *server) requests(ctx context.Context, batch ex.Batch) ([]ex.Request, error) {
 
 	var err error
 	var reqs []ex.Request
@@ -179,6 +400,13 @@ func (s *server) batch(ctx context.Context, batch ex.Batch) ([]map[string]any, e
 				}
 			}
 			reqs = append(reqs, c)
+
+		case ex.Load:
+			// Interceptors can only modify commands, so they would be bypassed
+			if len(s.Interceptors) > 0 {
+				return nil, errors.New("bulk load is not supported with interceptors")
+			}
+			reqs = append(reqs, c)
 		}
 	}
 
@@ -186,30 +414,63 @@ func (s *server) batch(ctx context.Context, batch ex.Batch) ([]map[string]any, e
 		reqs = append(reqs, ex.System(fmt.Sprintf("SET @%s = NULL", key)))
 	}
 
-	var data []map[string]any
-	if err = s.Client.ExecContext(ctx, ex.Bulk(reqs...), &data); err != nil {
-		return nil, err
-	}
+	return reqs, nil
+}
 
-	for _, p := range s.Processors {
-		data, err = p.Process(ctx, data)
-		if err != nil {
-			return nil, err
-		}
+// rowWriter encodes rows as they're written, sending the status and headers
+// with the first row.
+type rowWriter struct {
+	w       http.ResponseWriter
+	encoder Encoder
+	rows    RowEncoder
+	started bool
+}
+
+func (r *rowWriter) WriteRow(row map[string]any) error {
+	r.start()
+	return r.rows.Encode(row)
+}
+
+func (r *rowWriter) Close() error {
+	r.start()
+	return r.rows.Close()
+}
+
+func (r *rowWriter) start() {
+	if r.started {
+		return
 	}
 
-	return data, nil
+	r.started = true
+	r.w.Header().Set("Content-Type", r.encoder.ContentType())
+	r.w.WriteHeader(http.StatusOK)
+	r.rows = r.encoder.NewRowEncoder(r.w)
 }
 
 func (s *server) statusCode(err error) int {
 	switch t := err.(type) {
 	case *statusError:
 		return t.StatusCode
+	case *ex.Error:
+		if statusCode, ok := errorStatusCodes[t.Code]; ok {
+			return statusCode
+		}
+		return http.StatusBadRequest
 	default:
 		return http.StatusBadRequest
 	}
 }
 
+var errorStatusCodes = map[ex.ErrorCode]int{
+	ex.NotFound:            http.StatusNotFound,
+	ex.Conflict:            http.StatusConflict,
+	ex.ConstraintViolation: http.StatusConflict,
+	ex.Invalid:             http.StatusBadRequest,
+	ex.Unavailable:         http.StatusServiceUnavailable,
+	ex.Deadlock:            http.StatusConflict,
+	ex.Timeout:             http.StatusGatewayTimeout,
+}
+
 func (s *server) errorMessage(err error) map[string]any {
 	switch t := err.(type) {
 	case *statusError:
@@ -222,6 +483,18 @@ func (s *server) errorMessage(err error) map[string]any {
 			"error_code":    t.Number,
 			"error_message": t.Message,
 		}
+	case *pq.Error:
+		return map[string]any{
+			"error_code":    string(t.Code),
+			"error_message": t.Message,
+		}
+	case *ex.Error:
+		message := map[string]any{"error_message": t.Message}
+		if t.Err != nil {
+			message = s.errorMessage(t.Err)
+		}
+		message["error_type"] = t.Code
+		return message
 	default:
 		return map[string]any{
 			"error_message": err.Error(),
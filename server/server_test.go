@@ -2,6 +2,7 @@ package server_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"io"
 	"net/http"
 
@@ -105,7 +106,7 @@ var _ = Describe("Server", func() {
 						})
 
 						It("errors", func() {
-							Expect(response.StatusCode).To(Equal(http.StatusBadRequest))
+							Expect(response.StatusCode).To(Equal(http.StatusConflict))
 						})
 					})
 
@@ -161,7 +162,7 @@ var _ = Describe("Server", func() {
 						})
 
 						It("errors", func() {
-							Expect(response.StatusCode).To(Equal(http.StatusBadRequest))
+							Expect(response.StatusCode).To(Equal(http.StatusConflict))
 						})
 
 						It("contains expected items", func() {
@@ -258,6 +259,34 @@ var _ = Describe("Server", func() {
 						))
 					})
 				})
+
+				Context("returning the result of each command", func() {
+					BeforeEach(func() {
+						request.Body = io.NopCloser(
+							bytes.NewBufferString(`{"requests": [
+							  {"action": "DELETE", "resource": "resources", "where": {"name": "resource-1"}},
+							  {"action": "QUERY", "resource": "resources", "where": {"name": "resource-2"}},
+							  {"action": "INSERT", "resource": "resources", "values": {"name": "resource-4"}}
+							], "results": true}`),
+						)
+					})
+
+					It("succeeds", func() {
+						Expect(response.StatusCode).To(Equal(http.StatusOK))
+					})
+
+					It("returns the result of each command", func() {
+						var results []struct {
+							Data []resource `json:"data"`
+						}
+						Expect(json.NewDecoder(response.Body).Decode(&results)).To(Succeed())
+
+						Expect(results).To(HaveLen(3))
+						Expect(results[0].Data).To(ConsistOf(newResource(1, "resource-1")))
+						Expect(results[1].Data).To(ConsistOf(newResource(2, "resource-2")))
+						Expect(results[2].Data).To(ConsistOf(newResource(4, "resource-4")))
+					})
+				})
 			})
 		})
 	})
@@ -0,0 +1,94 @@
+package server_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/reverted/ex"
+	"github.com/reverted/ex/server"
+)
+
+var _ = Describe("Streaming", func() {
+
+	var (
+		err  error
+		body []byte
+
+		client       *fakeStreamClient
+		streamServer *httptest.Server
+	)
+
+	BeforeEach(func() {
+		client = &fakeStreamClient{rows: []map[string]any{{"id": 1}, {"id": 2}}}
+	})
+
+	JustBeforeEach(func() {
+		streamServer = httptest.NewServer(server.New(newLogger(), client,
+			server.WithTracer(noopTracer{}),
+		))
+
+		request, requestErr := http.NewRequest("GET", streamServer.URL+"/v1/resources", nil)
+		Expect(requestErr).NotTo(HaveOccurred())
+		request.Header.Set("Accept", "application/x-ndjson")
+
+		// The abort fails either the response or the read of its body,
+		// depending on how much of it was flushed
+		var response *http.Response
+		response, err = streamServer.Client().Do(request)
+		if err == nil {
+			defer response.Body.Close()
+			body, err = io.ReadAll(response.Body)
+		}
+	})
+
+	AfterEach(func() {
+		streamServer.Close()
+	})
+
+	It("streams every row", func() {
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(body)).To(Equal("{\"id\":1}\n{\"id\":2}\n"))
+	})
+
+	Context("when the stream fails after the first row", func() {
+		BeforeEach(func() {
+			client.err = errors.New("nope")
+		})
+
+		It("aborts the response", func() {
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
+
+type fakeStreamClient struct {
+	rows []map[string]any
+	err  error
+}
+
+func (c *fakeStreamClient) ExecContext(ctx context.Context, req ex.Request, res ...any) error {
+
+	fn := res[0].(ex.StreamFunc)
+
+	if err := fn(c.rows[0]); err != nil {
+		return err
+	}
+
+	if c.err != nil {
+		return c.err
+	}
+
+	for _, row := range c.rows[1:] {
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
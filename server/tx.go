@@ -0,0 +1,226 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/reverted/ex"
+)
+
+type Transactor interface {
+	Begin(context.Context) (ex.TxExecutor, error)
+}
+
+// WithTransactor enables the :begin, :commit and :rollback endpoints. Requests
+// with an X-Transaction header run in the transaction it names.
+func WithTransactor(transactor Transactor) opt {
+	return func(s *server) {
+		s.Transactor = transactor
+	}
+}
+
+// WithTxIdleTimeout sets how long a transaction can go unused before it's
+// rolled back.
+func WithTxIdleTimeout(timeout time.Duration) opt {
+	return func(s *server) {
+		s.TxIdleTimeout = timeout
+	}
+}
+
+type transactions struct {
+	sync.Mutex
+	txs map[string]*transaction
+}
+
+type transaction struct {
+	ex.TxExecutor
+	timer *time.Timer
+	busy  int
+}
+
+// ExecContext runs the request in the transaction. Failures that could
+// succeed if the whole transaction was retried are reported as conflicts,
+// since only the caller can retry it.
+func (t *transaction) ExecContext(ctx context.Context, req ex.Request, res ...any) error {
+
+	var data any
+	if len(res) > 0 {
+		data = res[0]
+	}
+
+	retry, err := t.Execute(ctx, req, data)
+	if retry && ex.CodeOf(err) == "" {
+		return ex.NewError(ex.Conflict, err)
+	}
+
+	return err
+}
+
+func (s *server) serveTx(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+	id := r.Header.Get("X-Transaction")
+
+	switch action := path.Base(r.URL.Path); {
+	case r.Method != "POST":
+		err = NewStatusError(http.StatusMethodNotAllowed, errors.New("unsupported method '"+r.Method+"'"))
+
+	case action == ":begin":
+		id, err = s.begin(r)
+
+	case action == ":commit":
+		err = s.end(id, true)
+
+	case action == ":rollback":
+		err = s.end(id, false)
+	}
+
+	if err != nil {
+		s.Logger.Error(err)
+		s.writeError(w, r, err)
+		return
+	}
+
+	s.Logger.Infof("<<< %v : %v [204]", r.Method, r.URL)
+
+	w.Header().Set("X-Transaction", id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *server) begin(r *http.Request) (string, error) {
+
+	if s.Transactor == nil {
+		return "", NewStatusError(http.StatusNotImplemented, errors.New("transactions are not supported"))
+	}
+
+	opts, err := parseTxOptions(r)
+	if err != nil {
+		return "", NewStatusError(http.StatusBadRequest, err)
+	}
+
+	// The transaction outlives the request that began it
+	ctx := ex.WithTxOptions(context.WithoutCancel(r.Context()), opts)
+
+	executor, err := s.Transactor.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		executor.Rollback()
+		return "", err
+	}
+
+	id := hex.EncodeToString(b)
+	tx := &transaction{TxExecutor: executor}
+
+	s.Transactions.Lock()
+	defer s.Transactions.Unlock()
+
+	tx.timer = time.AfterFunc(s.TxIdleTimeout, func() {
+		s.expire(id, tx)
+	})
+
+	s.Transactions.txs[id] = tx
+	return id, nil
+}
+
+func parseTxOptions(r *http.Request) (ex.TxOptions, error) {
+
+	opts := ex.TxOptions{
+		Isolation: ex.Isolation(strings.ToUpper(r.Header.Get("X-Isolation"))),
+	}
+
+	switch opts.Isolation {
+	case "", ex.ReadCommitted, ex.RepeatableRead, ex.Serializable:
+	default:
+		return opts, errors.New("unsupported isolation level: " + string(opts.Isolation))
+	}
+
+	if readOnly := r.Header.Get("X-Read-Only"); readOnly != "" {
+		value, err := strconv.ParseBool(readOnly)
+		if err != nil {
+			return opts, err
+		}
+		opts.ReadOnly = value
+	}
+
+	return opts, nil
+}
+
+func (s *server) expire(id string, tx *transaction) {
+
+	s.Transactions.Lock()
+	if s.Transactions.txs[id] != tx {
+		s.Transactions.Unlock()
+		return
+	}
+	delete(s.Transactions.txs, id)
+	s.Transactions.Unlock()
+
+	s.Logger.Infof("rolling back idle transaction %s", id)
+
+	if err := tx.Rollback(); err != nil {
+		s.Logger.Error(err)
+	}
+}
+
+// acquire stops the idle timer of the transaction until it's released.
+func (s *server) acquire(id string) (*transaction, error) {
+
+	s.Transactions.Lock()
+	defer s.Transactions.Unlock()
+
+	tx, ok := s.Transactions.txs[id]
+	if !ok {
+		return nil, NewStatusError(http.StatusNotFound, errors.New("unknown transaction: "+id))
+	}
+
+	if tx.busy == 0 && !tx.timer.Stop() {
+		return nil, NewStatusError(http.StatusNotFound, errors.New("expired transaction: "+id))
+	}
+
+	tx.busy++
+	return tx, nil
+}
+
+func (s *server) release(tx *transaction) {
+
+	s.Transactions.Lock()
+	defer s.Transactions.Unlock()
+
+	if tx.busy--; tx.busy == 0 {
+		tx.timer.Reset(s.TxIdleTimeout)
+	}
+}
+
+func (s *server) end(id string, commit bool) error {
+
+	tx, err := s.acquire(id)
+	if err != nil {
+		return err
+	}
+
+	s.Transactions.Lock()
+	delete(s.Transactions.txs, id)
+	s.Transactions.Unlock()
+
+	if !commit {
+		return tx.Rollback()
+	}
+
+	if retry, err := tx.Commit(); retry && ex.CodeOf(err) == "" {
+		return ex.NewError(ex.Conflict, err)
+	} else {
+		return err
+	}
+}
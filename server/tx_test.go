@@ -0,0 +1,223 @@
+package server_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/lib/pq"
+	"github.com/reverted/ex"
+	"github.com/reverted/ex/server"
+)
+
+var _ = Describe("Transactions", func() {
+
+	var (
+		response *http.Response
+
+		transactor *fakeTransactor
+		txServer   *httptest.Server
+	)
+
+	post := func(path, id string, body string, headers ...string) *http.Response {
+		request, err := http.NewRequest("POST", txServer.URL+"/v1/"+path, bytes.NewBufferString(body))
+		Expect(err).NotTo(HaveOccurred())
+
+		if id != "" {
+			request.Header.Set("X-Transaction", id)
+		}
+
+		for i := 0; i+1 < len(headers); i += 2 {
+			request.Header.Set(headers[i], headers[i+1])
+		}
+
+		response, err := txServer.Client().Do(request)
+		Expect(err).NotTo(HaveOccurred())
+		response.Body.Close()
+		return response
+	}
+
+	BeforeEach(func() {
+		transactor = &fakeTransactor{}
+	})
+
+	JustBeforeEach(func() {
+		if transactor != nil {
+			txServer = httptest.NewServer(server.New(newLogger(), fakeClient{},
+				server.WithTracer(noopTracer{}),
+				server.WithTransactor(transactor),
+				server.WithTxIdleTimeout(100*time.Millisecond),
+			))
+		} else {
+			txServer = httptest.NewServer(server.New(newLogger(), fakeClient{},
+				server.WithTracer(noopTracer{}),
+			))
+		}
+
+		response = post(":begin", "", "")
+	})
+
+	AfterEach(func() {
+		txServer.Close()
+	})
+
+	It("begins a transaction", func() {
+		Expect(response.StatusCode).To(Equal(http.StatusNoContent))
+		Expect(response.Header.Get("X-Transaction")).NotTo(BeEmpty())
+		Expect(transactor.txs).To(HaveLen(1))
+		Expect(transactor.txs[0].opts).To(Equal(ex.TxOptions{}))
+	})
+
+	Context("when beginning with options", func() {
+		It("begins the transaction with the options", func() {
+			response := post(":begin", "", "", "X-Isolation", "serializable", "X-Read-Only", "true")
+			Expect(response.StatusCode).To(Equal(http.StatusNoContent))
+			Expect(transactor.txs[1].opts).To(Equal(ex.TxOptions{Isolation: ex.Serializable, ReadOnly: true}))
+		})
+
+		It("rejects unsupported isolation levels", func() {
+			response := post(":begin", "", "", "X-Isolation", "snapshot")
+			Expect(response.StatusCode).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Context("when requests name the transaction", func() {
+		var id string
+
+		JustBeforeEach(func() {
+			id = response.Header.Get("X-Transaction")
+
+			Expect(post("resources", id, `{"name": "resource-1"}`).StatusCode).To(Equal(http.StatusOK))
+			Expect(post("resources", id, `{"name": "resource-2"}`).StatusCode).To(Equal(http.StatusOK))
+		})
+
+		It("runs them in the transaction", func() {
+			Expect(transactor.txs[0].requests).To(HaveLen(2))
+		})
+
+		It("commits the transaction", func() {
+			Expect(post(":commit", id, "").StatusCode).To(Equal(http.StatusNoContent))
+			Expect(transactor.txs[0].committed).To(BeTrue())
+			Expect(post("resources", id, `{}`).StatusCode).To(Equal(http.StatusNotFound))
+		})
+
+		It("rolls back the transaction", func() {
+			Expect(post(":rollback", id, "").StatusCode).To(Equal(http.StatusNoContent))
+			Expect(transactor.txs[0].rolledBack.Load()).To(BeTrue())
+			Expect(post("resources", id, `{}`).StatusCode).To(Equal(http.StatusNotFound))
+		})
+
+		It("rolls back the transaction once it's idle", func() {
+			Eventually(transactor.txs[0].rolledBack.Load).Should(BeTrue())
+			Expect(post(":commit", id, "").StatusCode).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Context("when a request in the transaction conflicts", func() {
+		BeforeEach(func() {
+			transactor.conflict = true
+		})
+
+		It("responds with a conflict", func() {
+			id := response.Header.Get("X-Transaction")
+			Expect(post("resources", id, `{}`).StatusCode).To(Equal(http.StatusConflict))
+		})
+	})
+
+	Context("when a request in the transaction fails on postgres", func() {
+		BeforeEach(func() {
+			transactor.err = ex.NewError(ex.ConstraintViolation, &pq.Error{Code: "23505", Message: "duplicate key value"})
+		})
+
+		It("responds with the postgres error", func() {
+			request, err := http.NewRequest("POST", txServer.URL+"/v1/resources", bytes.NewBufferString(`{}`))
+			Expect(err).NotTo(HaveOccurred())
+			request.Header.Set("X-Transaction", response.Header.Get("X-Transaction"))
+
+			response, err := txServer.Client().Do(request)
+			Expect(err).NotTo(HaveOccurred())
+			defer response.Body.Close()
+
+			var body map[string]any
+			Expect(json.NewDecoder(response.Body).Decode(&body)).To(Succeed())
+
+			Expect(response.StatusCode).To(Equal(http.StatusConflict))
+			Expect(body).To(Equal(map[string]any{
+				"error_code":    "23505",
+				"error_message": "duplicate key value",
+				"error_type":    "constraint_violation",
+			}))
+		})
+	})
+
+	Context("when the transaction is unknown", func() {
+		It("responds with not found", func() {
+			Expect(post("resources", "some-id", `{}`).StatusCode).To(Equal(http.StatusNotFound))
+			Expect(post(":commit", "some-id", "").StatusCode).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Context("when there is no transactor", func() {
+		BeforeEach(func() {
+			transactor = nil
+		})
+
+		It("responds with not implemented", func() {
+			Expect(response.StatusCode).To(Equal(http.StatusNotImplemented))
+		})
+	})
+})
+
+type fakeClient struct{}
+
+func (c fakeClient) ExecContext(ctx context.Context, req ex.Request, res ...any) error {
+	return errors.New("not in a transaction")
+}
+
+type fakeTransactor struct {
+	txs      []*fakeTx
+	conflict bool
+	err      error
+}
+
+func (t *fakeTransactor) Begin(ctx context.Context) (ex.TxExecutor, error) {
+	opts, _ := ex.TxOptionsFromContext(ctx)
+	tx := &fakeTx{opts: opts, conflict: t.conflict, err: t.err}
+	t.txs = append(t.txs, tx)
+	return tx, nil
+}
+
+type fakeTx struct {
+	opts       ex.TxOptions
+	requests   []ex.Request
+	conflict   bool
+	err        error
+	committed  bool
+	rolledBack atomic.Bool
+}
+
+func (t *fakeTx) Execute(ctx context.Context, req ex.Request, data any) (bool, error) {
+	t.requests = append(t.requests, req)
+	if t.conflict {
+		return true, errors.New("deadlock")
+	}
+	return false, t.err
+}
+
+func (t *fakeTx) Commit() (bool, error) {
+	t.committed = true
+	return false, nil
+}
+
+func (t *fakeTx) Rollback() error {
+	t.rolledBack.Store(true)
+	return nil
+}
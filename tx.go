@@ -0,0 +1,32 @@
+package ex
+
+import "context"
+
+type txOptionsKey struct{}
+
+type Isolation string
+
+const (
+	ReadCommitted  Isolation = "READ COMMITTED"
+	RepeatableRead Isolation = "REPEATABLE READ"
+	Serializable   Isolation = "SERIALIZABLE"
+)
+
+// TxOptions configure the transactions requests run in. The zero value uses
+// the database's default isolation level and allows writes.
+type TxOptions struct {
+	Isolation Isolation
+	ReadOnly  bool
+}
+
+// WithTxOptions returns a context whose requests and transactions are begun
+// with the given options.
+func WithTxOptions(ctx context.Context, opts TxOptions) context.Context {
+	return context.WithValue(ctx, txOptionsKey{}, opts)
+}
+
+// TxOptionsFromContext returns the TxOptions registered with WithTxOptions.
+func TxOptionsFromContext(ctx context.Context) (TxOptions, bool) {
+	opts, ok := ctx.Value(txOptionsKey{}).(TxOptions)
+	return opts, ok
+}